@@ -12,6 +12,7 @@ import (
 	"github.com/Sokol111/ecommerce-image-service/internal/infrastructure/external/s3"
 	"github.com/Sokol111/ecommerce-image-service/internal/infrastructure/messaging/kafka"
 	"github.com/Sokol111/ecommerce-image-service/internal/infrastructure/persistence/mongo"
+	"github.com/Sokol111/ecommerce-image-service/internal/worker"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -37,6 +38,9 @@ var AppModules = fx.Options(
 	// Application Layer
 	application.Module(),
 
+	// Background jobs
+	worker.Module(),
+
 	// HTTP
 	http.NewHttpHandlerModule(),
 	swaggerui.NewSwaggerModule(swaggerui.SwaggerConfig{OpenAPIContent: api.OpenAPIDoc}),
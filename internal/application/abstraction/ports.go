@@ -2,29 +2,73 @@ package abstraction
 
 import (
 	"context"
+	"errors"
 	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
 )
 
 // Storage abstractions define contracts for external storage dependencies.
 // These interfaces are implemented in the infrastructure layer.
 
-// PresignPutObjectInput contains parameters for presigning a PUT request
+// PresignPutObjectInput contains parameters for presigning a PUT request.
+// Size and ChecksumSHA256 are signed when set, so the upload must match them exactly.
 type PresignPutObjectInput struct {
-	Key         string
-	ContentType string
+	Key            string
+	ContentType    string
+	Size           int64
+	ChecksumSHA256 string
 }
 
 // PresignPutObjectOutput contains the result of presigning
 type PresignPutObjectOutput struct {
 	URL        string
 	TTLSeconds int
+	// SignedHeaders are headers covered by the signature that the client must send as-is
+	SignedHeaders map[string]string
+}
+
+// PresignPostObjectInput contains parameters for presigning a browser-based POST upload.
+// The policy restricts the upload to exactly Key, ContentType and ChecksumSHA256 and to a size within MinSize..MaxSize.
+type PresignPostObjectInput struct {
+	Key            string
+	ContentType    string
+	ChecksumSHA256 string
+	MinSize        int64
+	MaxSize        int64
+}
+
+// PresignPostObjectOutput contains the POST target and the form fields to submit with the file
+type PresignPostObjectOutput struct {
+	URL        string
+	Fields     map[string]string
+	TTLSeconds int
+}
+
+// PresignUploadPartInput contains parameters for presigning a multipart upload part
+type PresignUploadPartInput struct {
+	Key        string
+	UploadID   string
+	PartNumber int32
+}
+
+// PresignUploadPartOutput contains the presigned part URL
+type PresignUploadPartOutput struct {
+	URL           string
+	TTLSeconds    int
+	SignedHeaders map[string]string
 }
 
 // Presigner creates presigned URLs for uploading objects
 type Presigner interface {
 	PresignPutObject(ctx context.Context, input *PresignPutObjectInput) (*PresignPutObjectOutput, error)
+	PresignPostObject(ctx context.Context, input *PresignPostObjectInput) (*PresignPostObjectOutput, error)
+	PresignUploadPart(ctx context.Context, input *PresignUploadPartInput) (*PresignUploadPartOutput, error)
 }
 
+// ErrObjectNotFound is returned by ObjectStorage reads of a key that holds no object
+var ErrObjectNotFound = errors.New("object not found")
+
 // HeadObjectInput contains parameters for checking object metadata
 type HeadObjectInput struct {
 	Key string
@@ -32,7 +76,20 @@ type HeadObjectInput struct {
 
 // HeadObjectOutput contains object metadata
 type HeadObjectOutput struct {
-	ContentLength *int64
+	ContentLength  *int64
+	ChecksumSHA256 *string // base64-encoded, nil when the object was stored without a checksum
+}
+
+// GetObjectRangeInput contains parameters for reading a byte range of an object
+type GetObjectRangeInput struct {
+	Key    string
+	Offset int64
+	Length int64
+}
+
+// GetObjectRangeOutput contains the bytes read from the object
+type GetObjectRangeOutput struct {
+	Body []byte
 }
 
 // DeleteObjectInput contains parameters for deleting an object
@@ -46,11 +103,85 @@ type CopyObjectInput struct {
 	TargetKey string
 }
 
+// CreateMultipartUploadInput contains parameters for initiating a multipart upload
+type CreateMultipartUploadInput struct {
+	Key         string
+	ContentType string
+}
+
+// CreateMultipartUploadOutput contains the initiated upload
+type CreateMultipartUploadOutput struct {
+	UploadID string
+}
+
+// CompletedPart describes an uploaded part as reported by the client
+type CompletedPart struct {
+	PartNumber     int32
+	ETag           string
+	ChecksumSHA256 string
+}
+
+// CompleteMultipartUploadInput contains parameters for assembling the uploaded parts
+type CompleteMultipartUploadInput struct {
+	Key      string
+	UploadID string
+	Parts    []CompletedPart
+}
+
+// AbortMultipartUploadInput contains parameters for aborting a multipart upload
+type AbortMultipartUploadInput struct {
+	Key      string
+	UploadID string
+}
+
+// ListMultipartUploadsInput contains parameters for listing in-progress multipart uploads
+type ListMultipartUploadsInput struct {
+	Prefix string
+}
+
+// MultipartUpload describes an in-progress multipart upload
+type MultipartUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+}
+
+// ListMultipartUploadsOutput contains all in-progress uploads under the prefix
+type ListMultipartUploadsOutput struct {
+	Uploads []MultipartUpload
+}
+
+// ListObjectsInput contains parameters for listing one page of objects
+type ListObjectsInput struct {
+	Prefix            string
+	ContinuationToken string // empty for the first page
+	MaxKeys           int32
+}
+
+// StoredObject describes an object in storage
+type StoredObject struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// ListObjectsOutput contains one page of objects under the prefix
+type ListObjectsOutput struct {
+	Objects               []StoredObject
+	NextContinuationToken string // empty on the last page
+}
+
 // ObjectStorage provides operations for object storage
 type ObjectStorage interface {
 	HeadObject(ctx context.Context, input *HeadObjectInput) (*HeadObjectOutput, error)
+	GetObjectRange(ctx context.Context, input *GetObjectRangeInput) (*GetObjectRangeOutput, error)
 	DeleteObject(ctx context.Context, input *DeleteObjectInput) error
 	CopyObject(ctx context.Context, input *CopyObjectInput) error
+	CreateMultipartUpload(ctx context.Context, input *CreateMultipartUploadInput) (*CreateMultipartUploadOutput, error)
+	CompleteMultipartUpload(ctx context.Context, input *CompleteMultipartUploadInput) error
+	AbortMultipartUpload(ctx context.Context, input *AbortMultipartUploadInput) error
+	ListMultipartUploads(ctx context.Context, input *ListMultipartUploadsInput) (*ListMultipartUploadsOutput, error)
+	ListObjects(ctx context.Context, input *ListObjectsInput) (*ListObjectsOutput, error)
 }
 
 // SignerOptions contains parameters for building image transformation URLs
@@ -62,9 +193,100 @@ type SignerOptions struct {
 	DPR     *float32
 	Format  *string    // webp | avif | jpeg | png | "" (original)
 	Expires *time.Time // expiration time for signed URLs
+	Preset  *string    // imgproxy preset defined on the imgproxy side (pr:)
+
+	Gravity           *Gravity // where to anchor fill and crop
+	Crop              *Crop    // region cut out before resizing
+	Padding           *Padding // space added around the result
+	Background        *string  // hex RGB fill for padding and transparency, e.g. "ffffff"
+	Rotate            *int     // 0 | 90 | 180 | 270, after the EXIF orientation
+	Flip              *Flip
+	Blur              *float32 // gaussian sigma
+	Sharpen           *float32 // gaussian sigma
+	Trim              *float32 // threshold of the uniform border color to cut away
+	StripMetadata     *bool
+	StripColorProfile *bool
+	CacheBuster       *string // changes the URL without changing the image
+
+	// Not rendered as options; they select the watermark policy of the URL
+	OwnerType      string // owner type of the image
+	DeliveryPreset string // name of the delivery preset the options were resolved from
+	Caller         string // authenticated client the URL is built for; empty for anonymous requests
+}
+
+// Gravity types
+const (
+	GravityCenter     = "ce"
+	GravityNorth      = "no"
+	GravitySouth      = "so"
+	GravityEast       = "ea"
+	GravityWest       = "we"
+	GravityNorthEast  = "noea"
+	GravityNorthWest  = "nowe"
+	GravitySouthEast  = "soea"
+	GravitySouthWest  = "sowe"
+	GravitySmart      = "sm" // detected by imgproxy from the image content
+	GravityFocalPoint = "fp" // X and Y are relative coordinates, 0..1
+)
+
+// Gravity anchors fill and crop. X and Y are offsets in pixels for the compass types
+// and relative coordinates of the focal point for GravityFocalPoint.
+type Gravity struct {
+	Type string
+	X    float64
+	Y    float64
+}
+
+// Crop cuts out a region of Width x Height pixels, or a fraction of the image for values below 1
+type Crop struct {
+	Width   float64
+	Height  float64
+	Gravity *Gravity // defaults to the options gravity
+}
+
+// Padding in pixels, applied after resizing
+type Padding struct {
+	Top    int
+	Right  int
+	Bottom int
+	Left   int
+}
+
+// Flip mirrors the image
+type Flip struct {
+	Horizontal bool
+	Vertical   bool
 }
 
 // ImgproxySigner builds signed URLs for image transformation service
 type ImgproxySigner interface {
 	BuildURL(key string, opts SignerOptions) string
+	// VerifyURL checks the signature of a URL against every configured key.
+	// It returns an error wrapping image.ErrMalformedDeliveryURL when the URL has no signature.
+	VerifyURL(url string) (SignatureCheck, error)
+}
+
+// SignatureCheck reports which configured signing key signed a URL
+type SignatureCheck struct {
+	Valid  bool
+	KeyID  string // empty when no configured key matches
+	Active bool   // signed with the key currently used for new URLs
+}
+
+// DerivativeGenerator renders the standard variants of an uploaded image.
+// It returns an error wrapping image.ErrImageRejected when the source cannot be processed.
+type DerivativeGenerator interface {
+	Generate(ctx context.Context, key string) error
+}
+
+// PerceptualHasher computes the perceptual hash of a stored image.
+// It returns an error wrapping image.ErrImageRejected when the source cannot be processed.
+type PerceptualHasher interface {
+	Hash(ctx context.Context, key string) (string, error)
+}
+
+// ImageEventOutbox stores image events for asynchronous publishing. Append must be called
+// with the transaction context of the change the event describes.
+type ImageEventOutbox interface {
+	Append(ctx context.Context, img *image.Image, event image.Event) error
 }
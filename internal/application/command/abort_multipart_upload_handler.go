@@ -0,0 +1,110 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"go.uber.org/zap"
+)
+
+// AbortMultipartUploadCommand represents a request to cancel a multipart upload
+type AbortMultipartUploadCommand struct {
+	OwnerType string
+	OwnerID   string
+	Key       string
+	UploadID  string
+}
+
+// AbortMultipartUploadCommandHandler handles AbortMultipartUploadCommand
+type AbortMultipartUploadCommandHandler interface {
+	Handle(ctx context.Context, cmd AbortMultipartUploadCommand) error
+}
+
+// AbortStaleMultipartUploadsCommand represents a request to abort multipart uploads
+// that were started before OlderThan ago and never completed
+type AbortStaleMultipartUploadsCommand struct {
+	OlderThan time.Duration
+}
+
+// AbortStaleMultipartUploadsCommandHandler handles AbortStaleMultipartUploadsCommand
+type AbortStaleMultipartUploadsCommandHandler interface {
+	Handle(ctx context.Context, cmd AbortStaleMultipartUploadsCommand) (int, error)
+}
+
+type abortMultipartUploadHandler struct {
+	objStorage abstraction.ObjectStorage
+}
+
+func NewAbortMultipartUploadHandler(storage abstraction.ObjectStorage) AbortMultipartUploadCommandHandler {
+	return &abortMultipartUploadHandler{
+		objStorage: storage,
+	}
+}
+
+func (h *abortMultipartUploadHandler) Handle(ctx context.Context, cmd AbortMultipartUploadCommand) error {
+	if err := verifyOwnerKey(cmd.OwnerType, cmd.OwnerID, cmd.Key); err != nil {
+		return err
+	}
+
+	err := h.objStorage.AbortMultipartUpload(ctx, &abstraction.AbortMultipartUploadInput{
+		Key:      cmd.Key,
+		UploadID: cmd.UploadID,
+	})
+	if err != nil {
+		return fmt.Errorf("abort multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+type abortStaleMultipartUploadsHandler struct {
+	objStorage abstraction.ObjectStorage
+}
+
+func NewAbortStaleMultipartUploadsHandler(storage abstraction.ObjectStorage) AbortStaleMultipartUploadsCommandHandler {
+	return &abortStaleMultipartUploadsHandler{
+		objStorage: storage,
+	}
+}
+
+func (h *abortStaleMultipartUploadsHandler) Handle(ctx context.Context, cmd AbortStaleMultipartUploadsCommand) (int, error) {
+	cutoff := time.Now().Add(-cmd.OlderThan)
+	aborted := 0
+
+	for _, prefix := range ownerPrefixes() {
+		out, err := h.objStorage.ListMultipartUploads(ctx, &abstraction.ListMultipartUploadsInput{
+			Prefix: prefix,
+		})
+		if err != nil {
+			return aborted, fmt.Errorf("list multipart uploads under %s: %w", prefix, err)
+		}
+
+		for _, u := range out.Uploads {
+			if u.Initiated.After(cutoff) {
+				continue
+			}
+			err := h.objStorage.AbortMultipartUpload(ctx, &abstraction.AbortMultipartUploadInput{
+				Key:      u.Key,
+				UploadID: u.UploadID,
+			})
+			if err != nil {
+				h.log(ctx).Warn("failed to abort stale multipart upload", zap.Error(err), zap.String("key", u.Key))
+				continue
+			}
+			aborted++
+		}
+	}
+
+	if aborted > 0 {
+		h.log(ctx).Info("stale multipart uploads aborted", zap.Int("count", aborted))
+	}
+
+	return aborted, nil
+}
+
+func (h *abortStaleMultipartUploadsHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "abort-stale-multipart-uploads-handler"))
+}
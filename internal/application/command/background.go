@@ -0,0 +1,59 @@
+package command
+
+import (
+	"context"
+	"sync"
+)
+
+// BackgroundRunner runs work that outlives the request that started it, bound to the lifetime
+// of the application: Stop cancels the work and waits for it to return.
+type BackgroundRunner struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	stopped bool
+}
+
+func NewBackgroundRunner() *BackgroundRunner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &BackgroundRunner{ctx: ctx, cancel: cancel}
+}
+
+// Go runs fn in a new goroutine unless the runner is stopped, and reports whether it did
+func (b *BackgroundRunner) Go(fn func(ctx context.Context)) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped {
+		return false
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		fn(b.ctx)
+	}()
+	return true
+}
+
+// Stop cancels the running work and waits for it until ctx is done
+func (b *BackgroundRunner) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	b.stopped = true
+	b.cancel()
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,48 @@
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBackgroundRunnerStop(t *testing.T) {
+	background := NewBackgroundRunner()
+
+	cancelled := make(chan struct{})
+	if !background.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		close(cancelled)
+	}) {
+		t.Fatal("work not started")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := background.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("Stop returned before the work did")
+	}
+
+	if background.Go(func(context.Context) {}) {
+		t.Error("work started after Stop")
+	}
+}
+
+func TestBackgroundRunnerStopTimesOut(t *testing.T) {
+	background := NewBackgroundRunner()
+	release := make(chan struct{})
+	defer close(release)
+	background.Go(func(context.Context) { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := background.Stop(ctx); err == nil {
+		t.Fatal("Stop did not give up on work ignoring cancellation")
+	}
+}
@@ -0,0 +1,90 @@
+package command
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"go.uber.org/zap"
+)
+
+// UploadedPart describes a part the client uploaded
+type UploadedPart struct {
+	PartNumber int32
+	ETag       string
+	Checksum   string // base64 SHA-256 sent as x-amz-checksum-sha256
+}
+
+// CompleteMultipartUploadCommand represents a request to assemble the uploaded parts.
+// The assembled object is then registered with ConfirmUpload like a single-PUT upload.
+type CompleteMultipartUploadCommand struct {
+	OwnerType string
+	OwnerID   string
+	Key       string
+	UploadID  string
+	Parts     []UploadedPart
+}
+
+// CompleteMultipartUploadCommandHandler handles CompleteMultipartUploadCommand
+type CompleteMultipartUploadCommandHandler interface {
+	Handle(ctx context.Context, cmd CompleteMultipartUploadCommand) error
+}
+
+type completeMultipartUploadHandler struct {
+	objStorage abstraction.ObjectStorage
+}
+
+func NewCompleteMultipartUploadHandler(storage abstraction.ObjectStorage) CompleteMultipartUploadCommandHandler {
+	return &completeMultipartUploadHandler{
+		objStorage: storage,
+	}
+}
+
+func (h *completeMultipartUploadHandler) Handle(ctx context.Context, cmd CompleteMultipartUploadCommand) error {
+	if err := verifyOwnerKey(cmd.OwnerType, cmd.OwnerID, cmd.Key); err != nil {
+		return err
+	}
+
+	if cmd.UploadID == "" || len(cmd.Parts) == 0 {
+		return fmt.Errorf("%w: upload ID and parts are required", image.ErrInvalidUploadParts)
+	}
+
+	// S3 requires parts in ascending order without duplicates
+	parts := make([]abstraction.CompletedPart, 0, len(cmd.Parts))
+	for _, p := range cmd.Parts {
+		parts = append(parts, abstraction.CompletedPart{
+			PartNumber:     p.PartNumber,
+			ETag:           p.ETag,
+			ChecksumSHA256: p.Checksum,
+		})
+	}
+	slices.SortFunc(parts, func(a, b abstraction.CompletedPart) int {
+		return cmp.Compare(a.PartNumber, b.PartNumber)
+	})
+	for i := 1; i < len(parts); i++ {
+		if parts[i].PartNumber == parts[i-1].PartNumber {
+			return fmt.Errorf("%w: duplicate part number %d", image.ErrInvalidUploadParts, parts[i].PartNumber)
+		}
+	}
+
+	err := h.objStorage.CompleteMultipartUpload(ctx, &abstraction.CompleteMultipartUploadInput{
+		Key:      cmd.Key,
+		UploadID: cmd.UploadID,
+		Parts:    parts,
+	})
+	if err != nil {
+		return fmt.Errorf("complete multipart upload: %w", err)
+	}
+
+	h.log(ctx).Debug("multipart upload completed", zap.String("key", cmd.Key), zap.Int("parts", len(parts)))
+
+	return nil
+}
+
+func (h *completeMultipartUploadHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "complete-multipart-upload-handler"))
+}
@@ -2,15 +2,24 @@ package command
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
 	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/processing"
 	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
 	"go.uber.org/zap"
 )
 
+// checksumReadChunk is how much of an object stored without a checksum is read at a time to hash it
+const checksumReadChunk = 8 << 20
+
 // ConfirmUploadCommand represents a request to confirm an image upload
 type ConfirmUploadCommand struct {
 	Alt       string
@@ -20,6 +29,10 @@ type ConfirmUploadCommand struct {
 	OwnerType string
 	OwnerID   string
 	Role      string
+
+	// IdempotencyKey identifies retries of the same confirmation. Without it the
+	// object key identifies them, as a key is confirmed once.
+	IdempotencyKey string
 }
 
 // ConfirmUploadCommandHandler handles ConfirmUploadCommand
@@ -28,29 +41,44 @@ type ConfirmUploadCommandHandler interface {
 }
 
 type confirmUploadHandler struct {
-	repo           image.Repository
-	objStorage     abstraction.ObjectStorage
-	maxUploadBytes int64
+	repo       image.Repository
+	objStorage abstraction.ObjectStorage
+	limits     UploadLimits
+
+	// hash and duplicates reject near-duplicates on confirm; nil unless the duplicate policy rejects them
+	hash       processing.Step
+	duplicates processing.Step
 }
 
-func NewConfirmUploadHandler(repo image.Repository, storage abstraction.ObjectStorage, maxUploadBytes int64) ConfirmUploadCommandHandler {
-	return &confirmUploadHandler{
-		repo:           repo,
-		objStorage:     storage,
-		maxUploadBytes: maxUploadBytes,
+func NewConfirmUploadHandler(
+	repo image.Repository,
+	storage abstraction.ObjectStorage,
+	hasher abstraction.PerceptualHasher,
+	limits UploadLimits,
+	duplicates processing.DuplicatePolicy,
+) ConfirmUploadCommandHandler {
+	h := &confirmUploadHandler{
+		repo:       repo,
+		objStorage: storage,
+		limits:     limits,
 	}
+	if duplicates.Action == processing.DuplicateActionReject {
+		h.hash = processing.NewHashStep(hasher)
+		h.duplicates = processing.NewDuplicatesStep(repo, duplicates)
+	}
+	return h
 }
 
 func (h *confirmUploadHandler) Handle(ctx context.Context, cmd ConfirmUploadCommand) (*image.Image, error) {
 	// Validate key matches expected owner prefix
-	prefix, err := getPrefixByOwnerType(cmd.OwnerType)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get prefix by owner type: %w", err)
+	if err := verifyOwnerKey(cmd.OwnerType, cmd.OwnerID, cmd.Key); err != nil {
+		return nil, err
 	}
-	expectedPrefix := prefix + cmd.OwnerID + "/"
 
-	if !strings.HasPrefix(cmd.Key, expectedPrefix) {
-		return nil, fmt.Errorf("key does not match expected owner prefix")
+	// A retried confirmation gets the image created by the first attempt
+	id := h.imageID(cmd)
+	if existing, err := h.replay(ctx, id, cmd); existing != nil || err != nil {
+		return existing, err
 	}
 
 	// Verify object exists in S3
@@ -66,23 +94,99 @@ func (h *confirmUploadHandler) Handle(ctx context.Context, cmd ConfirmUploadComm
 		size = *ho.ContentLength
 	}
 
-	// Validate size
-	if h.maxUploadBytes > 0 && size > h.maxUploadBytes {
+	// Validate size; only objects assembled from parts may use the multipart limit
+	multipart := ho.ChecksumSHA256 != nil && image.IsCompositeChecksum(*ho.ChecksumSHA256)
+	maxBytes := h.limits.maxBytesFor(cmd.OwnerType, multipart)
+	if maxBytes > 0 && size > maxBytes {
+		_ = h.objStorage.DeleteObject(ctx, &abstraction.DeleteObjectInput{
+			Key: cmd.Key,
+		})
+		return nil, fmt.Errorf("%w: %d bytes, max %d bytes", image.ErrImageTooLarge, size, maxBytes)
+	}
+
+	// Verify integrity against the checksum S3 computed on upload. Objects uploaded without
+	// one, with URLs presigned before checksums were signed or by clients that skipped the
+	// header, are hashed here instead of being rejected.
+	stored := ""
+	if ho.ChecksumSHA256 != nil {
+		stored = *ho.ChecksumSHA256
+	}
+	if stored == "" {
+		if stored, err = h.computeChecksum(ctx, cmd.Key, size); err != nil {
+			return nil, err
+		}
+	}
+	checksum, err := verifyChecksum(stored, cmd.Checksum)
+	if err != nil {
+		if errors.Is(err, image.ErrChecksumMismatch) {
+			_ = h.objStorage.DeleteObject(ctx, &abstraction.DeleteObjectInput{
+				Key: cmd.Key,
+			})
+		}
+		return nil, err
+	}
+
+	// Verify the real content type from the leading bytes
+	head, err := h.readHeader(ctx, cmd.Key, size)
+	if err != nil {
+		return nil, err
+	}
+	detected := image.DetectMime(head)
+	if detected == "" || !strings.EqualFold(detected, cmd.Mime) || !image.MimeMatchesKey(detected, cmd.Key) {
+		_ = h.objStorage.DeleteObject(ctx, &abstraction.DeleteObjectInput{
+			Key: cmd.Key,
+		})
+		return nil, fmt.Errorf("%w: declared %s, detected %q", image.ErrContentTypeMismatch, cmd.Mime, detected)
+	}
+
+	// Read the pixel size and reject decompression bombs and non-square avatars
+	dims, err := processing.ReadObjectDimensions(ctx, h.objStorage, cmd.Key, detected, size, head)
+	if err == nil {
+		err = h.validateDimensions(dims, cmd.OwnerType)
+	}
+	if err != nil {
+		if !errors.Is(err, image.ErrUnreadableDimensions) && !errors.Is(err, image.ErrTooManyPixels) &&
+			!errors.Is(err, image.ErrInvalidAspectRatio) {
+			return nil, fmt.Errorf("read dimensions: %w", err)
+		}
 		_ = h.objStorage.DeleteObject(ctx, &abstraction.DeleteObjectInput{
 			Key: cmd.Key,
 		})
-		return nil, fmt.Errorf("file too large: max %d bytes", h.maxUploadBytes)
+		return nil, err
 	}
 
 	// Create domain image
-	img, err := image.NewImage(cmd.Alt, cmd.OwnerType, cmd.OwnerID, cmd.Role, cmd.Key, cmd.Mime, size)
+	img, err := image.NewImageWithID(id, cmd.Alt, cmd.OwnerType, cmd.OwnerID, cmd.Role, cmd.Key, cmd.Mime, size)
 	if err != nil {
 		return nil, fmt.Errorf("create image: %w", err)
 	}
+	img.RecordDetectedMime(detected)
+	img.RecordChecksum(checksum)
+	img.RecordDimensions(dims)
+
+	if err := h.rejectDuplicate(ctx, img); err != nil {
+		if errors.Is(err, image.ErrDuplicateImage) {
+			_ = h.objStorage.DeleteObject(ctx, &abstraction.DeleteObjectInput{
+				Key: cmd.Key,
+			})
+		}
+		return nil, err
+	}
+
+	// Hashing, duplicate detection and derivatives are left to the processing worker
+	img.MarkAsProcessing()
 
-	// Save to repository
+	// Save to repository; a concurrent attempt of the same confirmation may have won
 	if err := h.repo.Save(ctx, img); err != nil {
-		return nil, fmt.Errorf("save image: %w", err)
+		if !errors.Is(err, image.ErrImageAlreadyExists) {
+			return nil, fmt.Errorf("save image: %w", err)
+		}
+		existing, replayErr := h.replay(ctx, id, cmd)
+		if replayErr == nil && existing == nil {
+			// The winning attempt's image was deleted in the meantime
+			return nil, err
+		}
+		return existing, replayErr
 	}
 
 	h.log(ctx).Debug("image upload confirmed", zap.String("id", img.ID), zap.String("key", img.Key))
@@ -90,6 +194,139 @@ func (h *confirmUploadHandler) Handle(ctx context.Context, cmd ConfirmUploadComm
 	return img, nil
 }
 
+// imageID derives the ID of the image from the idempotency key or, without one, from the object key
+func (h *confirmUploadHandler) imageID(cmd ConfirmUploadCommand) string {
+	if cmd.IdempotencyKey != "" {
+		return idempotentID("confirm", cmd.OwnerType, cmd.OwnerID, cmd.IdempotencyKey)
+	}
+	return idempotentID("confirm", cmd.Key)
+}
+
+// replay returns the image stored by an earlier attempt of the confirmation, or nil if there is none.
+// Attempts with another idempotency key still share the object key.
+func (h *confirmUploadHandler) replay(ctx context.Context, id string, cmd ConfirmUploadCommand) (*image.Image, error) {
+	existing, err := h.repo.FindByID(ctx, id)
+	if errors.Is(err, persistence.ErrEntityNotFound) {
+		existing, err = h.repo.FindByKey(ctx, cmd.Key)
+	}
+	if err != nil {
+		if errors.Is(err, persistence.ErrEntityNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find confirmed image: %w", err)
+	}
+
+	if !confirmedFor(existing, cmd) {
+		return nil, fmt.Errorf("%w: image %s was confirmed for key %s", image.ErrIdempotencyKeyReused, existing.ID, existing.Key)
+	}
+	if existing.IsDeleted() {
+		return nil, fmt.Errorf("%w: %s", image.ErrImageAlreadyDeleted, existing.ID)
+	}
+
+	h.log(ctx).Debug("image upload confirmation replayed", zap.String("id", existing.ID), zap.String("key", existing.Key))
+	return existing, nil
+}
+
+// confirmedFor reports whether the image was confirmed for the object key of the command,
+// also when a promotion has since moved it from the draft to its product
+func confirmedFor(img *image.Image, cmd ConfirmUploadCommand) bool {
+	if img.OwnerType == cmd.OwnerType && img.OwnerID == cmd.OwnerID && img.Key == cmd.Key {
+		return true
+	}
+	// Promotion keeps the file name of the object and only changes the owner prefix
+	return cmd.OwnerType == image.OwnerTypeProductDraft &&
+		img.OwnerType == image.OwnerTypeProduct &&
+		path.Base(img.Key) == path.Base(cmd.Key)
+}
+
+// readHeader reads the leading bytes of the object, enough to detect its type and, for most
+// files, its dimensions
+func (h *confirmUploadHandler) readHeader(ctx context.Context, key string, size int64) ([]byte, error) {
+	if size == 0 {
+		return nil, nil
+	}
+
+	out, err := h.objStorage.GetObjectRange(ctx, &abstraction.GetObjectRangeInput{
+		Key:    key,
+		Offset: 0,
+		Length: min(size, image.HeaderReadLength),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read object header: %w", err)
+	}
+
+	return out.Body, nil
+}
+
+func (h *confirmUploadHandler) validateDimensions(dims image.Dimensions, ownerType string) error {
+	if err := image.ValidatePixelCount(dims, h.limits.MaxPixels); err != nil {
+		return err
+	}
+	if ownerType == image.OwnerTypeUser {
+		return image.ValidateAvatarDimensions(dims)
+	}
+	return nil
+}
+
+// rejectDuplicate fails the confirmation of a near-duplicate when the duplicate policy rejects them,
+// so the client does not have to poll the image to learn it failed. The worker checks again, which
+// catches concurrent uploads and images that could not be hashed here.
+func (h *confirmUploadHandler) rejectDuplicate(ctx context.Context, img *image.Image) error {
+	if h.duplicates == nil || img.OwnerType == image.OwnerTypeUser {
+		return nil
+	}
+
+	if err := h.hash.Run(ctx, img); err != nil {
+		h.log(ctx).Warn("failed to hash image on confirm", zap.Error(err), zap.String("key", img.Key))
+		return nil
+	}
+	return h.duplicates.Run(ctx, img)
+}
+
+// verifyChecksum compares the stored object checksum with the one declared by the client
+// and returns the stored checksum in normalized form
+func verifyChecksum(stored string, declared *string) (string, error) {
+	// Multipart uploads carry a checksum of part checksums that S3 verified part by part;
+	// it cannot be compared with a whole-file checksum
+	if image.IsCompositeChecksum(stored) {
+		return stored, nil
+	}
+
+	actual, err := image.NormalizeChecksum(stored)
+	if err != nil {
+		return "", fmt.Errorf("%w: unexpected stored checksum %q", image.ErrChecksumMismatch, stored)
+	}
+
+	if declared != nil && *declared != "" {
+		expected, err := image.NormalizeChecksum(*declared)
+		if err != nil {
+			return "", err
+		}
+		if expected != actual {
+			return "", fmt.Errorf("%w: declared %s, stored %s", image.ErrChecksumMismatch, expected, actual)
+		}
+	}
+
+	return actual, nil
+}
+
+// computeChecksum hashes an object stored without a SHA-256 checksum, in chunks
+func (h *confirmUploadHandler) computeChecksum(ctx context.Context, key string, size int64) (string, error) {
+	hash := sha256.New()
+	for offset := int64(0); offset < size; offset += checksumReadChunk {
+		out, err := h.objStorage.GetObjectRange(ctx, &abstraction.GetObjectRangeInput{
+			Key:    key,
+			Offset: offset,
+			Length: min(checksumReadChunk, size-offset),
+		})
+		if err != nil {
+			return "", fmt.Errorf("read object for checksum: %w", err)
+		}
+		hash.Write(out.Body)
+	}
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
+}
+
 func (h *confirmUploadHandler) log(ctx context.Context) *zap.Logger {
 	return logger.FromContext(ctx).With(zap.String("component", "confirm-upload-handler"))
 }
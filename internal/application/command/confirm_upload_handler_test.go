@@ -0,0 +1,344 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	stdimage "image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/processing"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// testJPEG encodes a small JPEG, enough for sniffing and dimension reading
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := stdimage.NewGray(stdimage.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.SetGray(x, 0, color.Gray{Y: uint8(x)})
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func newTestConfirmHandler(repo image.Repository, storage *fakeStorage) ConfirmUploadCommandHandler {
+	return NewConfirmUploadHandler(repo, storage, nil,
+		UploadLimits{MaxBytes: 1 << 20, MaxPixels: 1 << 20}, processing.DuplicatePolicy{Action: processing.DuplicateActionFlag})
+}
+
+func draftConfirm(key, idempotencyKey string) ConfirmUploadCommand {
+	return ConfirmUploadCommand{
+		Key:            key,
+		Mime:           "image/jpeg",
+		OwnerType:      image.OwnerTypeProductDraft,
+		OwnerID:        "d-1",
+		Role:           "gallery",
+		IdempotencyKey: idempotencyKey,
+	}
+}
+
+func TestConfirmUploadIdempotency(t *testing.T) {
+	const key = "product-drafts/d-1/0b6f.jpg"
+
+	tests := []struct {
+		name  string
+		first ConfirmUploadCommand
+		retry ConfirmUploadCommand
+	}{
+		{"retry without idempotency key", draftConfirm(key, ""), draftConfirm(key, "")},
+		{"retry with the same idempotency key", draftConfirm(key, "k-1"), draftConfirm(key, "k-1")},
+		{"retry with another idempotency key", draftConfirm(key, "k-1"), draftConfirm(key, "k-2")},
+		{"retry adding an idempotency key", draftConfirm(key, ""), draftConfirm(key, "k-1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeImageRepo()
+			storage := newFakeStorage()
+			storage.put(key, testJPEG(t, 8, 6))
+			h := newTestConfirmHandler(repo, storage)
+
+			first, err := h.Handle(context.Background(), tt.first)
+			if err != nil {
+				t.Fatalf("first confirm: %v", err)
+			}
+			retried, err := h.Handle(context.Background(), tt.retry)
+			if err != nil {
+				t.Fatalf("retried confirm: %v", err)
+			}
+
+			if retried.ID != first.ID {
+				t.Fatalf("retry returned image %s, want %s", retried.ID, first.ID)
+			}
+			if repo.count() != 1 {
+				t.Fatalf("stored %d images, want 1", repo.count())
+			}
+			if first.Width != 8 || first.Height != 6 {
+				t.Fatalf("dimensions: got %dx%d, want 8x6", first.Width, first.Height)
+			}
+		})
+	}
+}
+
+func TestConfirmUploadIdempotencyKeyReused(t *testing.T) {
+	repo := newFakeImageRepo()
+	storage := newFakeStorage()
+	storage.put("product-drafts/d-1/a.jpg", testJPEG(t, 4, 4))
+	storage.put("product-drafts/d-1/b.jpg", testJPEG(t, 4, 4))
+	h := newTestConfirmHandler(repo, storage)
+
+	if _, err := h.Handle(context.Background(), draftConfirm("product-drafts/d-1/a.jpg", "k-1")); err != nil {
+		t.Fatalf("first confirm: %v", err)
+	}
+	_, err := h.Handle(context.Background(), draftConfirm("product-drafts/d-1/b.jpg", "k-1"))
+	if !errors.Is(err, image.ErrIdempotencyKeyReused) {
+		t.Fatalf("got %v, want %v", err, image.ErrIdempotencyKeyReused)
+	}
+}
+
+func TestConfirmUploadReplaysPromotedImage(t *testing.T) {
+	const key = "product-drafts/d-1/0b6f.jpg"
+
+	for _, idempotencyKey := range []string{"", "k-1"} {
+		t.Run("idempotency key "+idempotencyKey, func(t *testing.T) {
+			repo := newFakeImageRepo()
+			storage := newFakeStorage()
+			storage.put(key, testJPEG(t, 4, 4))
+			h := newTestConfirmHandler(repo, storage)
+
+			first, err := h.Handle(context.Background(), draftConfirm(key, idempotencyKey))
+			if err != nil {
+				t.Fatalf("first confirm: %v", err)
+			}
+
+			promoted := repo.get(first.ID)
+			if err := promoted.PromoteToProduct("p-1", "products/p-1/0b6f.jpg"); err != nil {
+				t.Fatalf("promote: %v", err)
+			}
+			if _, err := repo.Update(context.Background(), promoted); err != nil {
+				t.Fatalf("update: %v", err)
+			}
+
+			retried, err := h.Handle(context.Background(), draftConfirm(key, idempotencyKey))
+			if err != nil {
+				t.Fatalf("retried confirm: %v", err)
+			}
+			if retried.ID != first.ID || retried.OwnerType != image.OwnerTypeProduct {
+				t.Fatalf("retry returned %s owned by %s, want promoted image %s", retried.ID, retried.OwnerType, first.ID)
+			}
+		})
+	}
+}
+
+// conflictingRepo loses every insert to an attempt whose image is gone by the time it is looked up
+type conflictingRepo struct {
+	*fakeImageRepo
+}
+
+func (r conflictingRepo) Save(context.Context, *image.Image) error {
+	return image.ErrImageAlreadyExists
+}
+
+func TestConfirmUploadLostRaceWithoutImage(t *testing.T) {
+	const key = "product-drafts/d-1/a.jpg"
+	storage := newFakeStorage()
+	storage.put(key, testJPEG(t, 4, 4))
+	h := newTestConfirmHandler(conflictingRepo{newFakeImageRepo()}, storage)
+
+	img, err := h.Handle(context.Background(), draftConfirm(key, ""))
+	if img != nil || !errors.Is(err, image.ErrImageAlreadyExists) {
+		t.Fatalf("got (%v, %v), want (nil, %v)", img, err, image.ErrImageAlreadyExists)
+	}
+}
+
+func TestConfirmUploadQueuesProcessing(t *testing.T) {
+	tests := []struct {
+		name       string
+		ownerType  string
+		ownerID    string
+		key        string
+		wantStatus image.ImageStatus
+	}{
+		{"draft images", image.OwnerTypeProductDraft, "d-1", "product-drafts/d-1/a.jpg", image.StatusProcessing},
+		{"product images", image.OwnerTypeProduct, "p-1", "products/p-1/a.jpg", image.StatusProcessing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeImageRepo()
+			storage := newFakeStorage()
+			storage.put(tt.key, testJPEG(t, 4, 4))
+			h := newTestConfirmHandler(repo, storage)
+
+			cmd := draftConfirm(tt.key, "")
+			cmd.OwnerType, cmd.OwnerID = tt.ownerType, tt.ownerID
+			img, err := h.Handle(context.Background(), cmd)
+			if err != nil {
+				t.Fatalf("confirm: %v", err)
+			}
+			if stored := repo.get(img.ID); stored.Status != tt.wantStatus {
+				t.Errorf("stored status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestConfirmUploadWithoutStoredChecksum(t *testing.T) {
+	const key = "product-drafts/d-1/a.jpg"
+	body := testJPEG(t, 4, 4)
+	sum := sha256.Sum256(body)
+	want := base64.StdEncoding.EncodeToString(sum[:])
+	wrong := "LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="
+
+	tests := []struct {
+		name     string
+		declared *string
+		wantErr  error
+	}{
+		{"hashed on confirm", nil, nil},
+		{"hashed and compared with the declared checksum", &want, nil},
+		{"hashed and mismatching the declared checksum", &wrong, image.ErrChecksumMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeImageRepo()
+			storage := newFakeStorage()
+			storage.noChecksum = true
+			storage.put(key, body)
+
+			cmd := draftConfirm(key, "")
+			cmd.Checksum = tt.declared
+			img, err := newTestConfirmHandler(repo, storage).Handle(context.Background(), cmd)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("confirm: %v", err)
+			}
+			if img.Checksum != want {
+				t.Fatalf("checksum = %s, want %s", img.Checksum, want)
+			}
+			if !storage.has(key) {
+				t.Fatal("object was deleted")
+			}
+		})
+	}
+}
+
+// fakeHasher hashes every object to the same perceptual hash, or fails
+type fakeHasher struct {
+	hash string
+	err  error
+}
+
+func (f fakeHasher) Hash(context.Context, string) (string, error) {
+	return f.hash, f.err
+}
+
+func TestConfirmUploadRejectsDuplicates(t *testing.T) {
+	const key = "products/p-1/b.jpg"
+
+	tests := []struct {
+		name     string
+		action   string
+		hasher   fakeHasher
+		wantErr  error
+		wantHash string
+	}{
+		{"rejects a near-duplicate", processing.DuplicateActionReject, fakeHasher{hash: "00000000000000fe"}, image.ErrDuplicateImage, ""},
+		{"accepts a distant image", processing.DuplicateActionReject, fakeHasher{hash: "0f0f0f0f0f0f0f0f"}, nil, "0f0f0f0f0f0f0f0f"},
+		{"leaves failed hashing to the worker", processing.DuplicateActionReject, fakeHasher{err: errors.New("imgproxy down")}, nil, ""},
+		{"leaves flagging to the worker", processing.DuplicateActionFlag, fakeHasher{hash: "00000000000000fe"}, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeImageRepo()
+			original := newTestImage(t, "original", image.OwnerTypeProduct, "p-1")
+			original.RecordPerceptualHash("00000000000000ff")
+			if err := repo.Save(context.Background(), original); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			storage := newFakeStorage()
+			storage.put(key, testJPEG(t, 4, 4))
+			h := NewConfirmUploadHandler(repo, storage, tt.hasher,
+				UploadLimits{MaxBytes: 1 << 20, MaxPixels: 1 << 20},
+				processing.DuplicatePolicy{Action: tt.action, MaxDistance: 2})
+
+			cmd := draftConfirm(key, "")
+			cmd.OwnerType, cmd.OwnerID = image.OwnerTypeProduct, "p-1"
+			img, err := h.Handle(context.Background(), cmd)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if storage.has(key) || repo.count() != 1 {
+					t.Fatal("rejected upload was kept")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("confirm: %v", err)
+			}
+			if img.PerceptualHash != tt.wantHash {
+				t.Errorf("perceptual hash = %q, want %q", img.PerceptualHash, tt.wantHash)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	stored := "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg="
+	hexOfStored := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	other := "LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="
+	composite := stored + "-3"
+	malformed := "abc"
+	empty := ""
+
+	tests := []struct {
+		name     string
+		stored   string
+		declared *string
+		want     string
+		wantErr  error
+	}{
+		{"stored only", stored, nil, stored, nil},
+		{"declared in base64", stored, &stored, stored, nil},
+		{"declared in hex", stored, &hexOfStored, stored, nil},
+		{"empty declaration is ignored", stored, &empty, stored, nil},
+		{"declared checksum differs", stored, &other, "", image.ErrChecksumMismatch},
+		{"malformed declaration", stored, &malformed, "", image.ErrInvalidChecksum},
+		{"multipart checksum is kept", composite, &other, composite, nil},
+		{"malformed stored checksum", malformed, nil, "", image.ErrChecksumMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifyChecksum(tt.stored, tt.declared)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verifyChecksum: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("verifyChecksum = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
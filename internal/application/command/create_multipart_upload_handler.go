@@ -0,0 +1,148 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"go.uber.org/zap"
+)
+
+// S3 limits for multipart uploads
+const (
+	minPartSize  = 5 * 1024 * 1024
+	maxPartCount = 10000
+)
+
+// CreateMultipartUploadCommand represents a request to start uploading a large image in parts
+type CreateMultipartUploadCommand struct {
+	ContentType string
+	Filename    string
+	OwnerType   string
+	OwnerID     string
+	Size        int64
+}
+
+// PresignedPart is a presigned URL for uploading a single part
+type PresignedPart struct {
+	PartNumber int32
+	URL        string
+}
+
+// CreateMultipartUploadResult contains the upload ID and the presigned URLs of all parts
+type CreateMultipartUploadResult struct {
+	Key             string
+	UploadID        string
+	PartSize        int64
+	Parts           []PresignedPart
+	ExpiresIn       int
+	RequiredHeaders map[string]string
+}
+
+// CreateMultipartUploadCommandHandler handles CreateMultipartUploadCommand
+type CreateMultipartUploadCommandHandler interface {
+	Handle(ctx context.Context, cmd CreateMultipartUploadCommand) (*CreateMultipartUploadResult, error)
+}
+
+type createMultipartUploadHandler struct {
+	presigner  abstraction.Presigner
+	objStorage abstraction.ObjectStorage
+	partSize   int64
+	limits     UploadLimits
+}
+
+func NewCreateMultipartUploadHandler(presigner abstraction.Presigner, storage abstraction.ObjectStorage, partSize int64, limits UploadLimits) CreateMultipartUploadCommandHandler {
+	return &createMultipartUploadHandler{
+		presigner:  presigner,
+		objStorage: storage,
+		partSize:   max(partSize, minPartSize),
+		limits:     limits,
+	}
+}
+
+func (h *createMultipartUploadHandler) Handle(ctx context.Context, cmd CreateMultipartUploadCommand) (*CreateMultipartUploadResult, error) {
+	ext := image.ExtensionForMime(cmd.ContentType)
+	if ext == "" {
+		return nil, fmt.Errorf("unsupported content type: %s", cmd.ContentType)
+	}
+
+	if cmd.Size <= 0 {
+		return nil, fmt.Errorf("%w: size is required for multipart uploads", image.ErrInvalidUploadParts)
+	}
+	maxBytes := h.limits.maxBytesFor(cmd.OwnerType, true)
+	if maxBytes > 0 && cmd.Size > maxBytes {
+		return nil, fmt.Errorf("%w: declared %d bytes, max %d bytes", image.ErrImageTooLarge, cmd.Size, maxBytes)
+	}
+
+	// Grow the part size for very large files to stay within the S3 part count limit
+	partSize := max(h.partSize, (cmd.Size+maxPartCount-1)/maxPartCount)
+	partCount := int32((cmd.Size + partSize - 1) / partSize)
+
+	key, err := newObjectKey(cmd.OwnerType, cmd.OwnerID, ext)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := h.objStorage.CreateMultipartUpload(ctx, &abstraction.CreateMultipartUploadInput{
+		Key:         key,
+		ContentType: cmd.ContentType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create multipart upload: %w", err)
+	}
+
+	partNumbers := make([]int32, 0, partCount)
+	for n := int32(1); n <= partCount; n++ {
+		partNumbers = append(partNumbers, n)
+	}
+
+	parts, ttl, headers, err := presignParts(ctx, h.presigner, key, out.UploadID, partNumbers)
+	if err != nil {
+		return nil, err
+	}
+
+	h.log(ctx).Debug("multipart upload created",
+		zap.String("key", key), zap.String("uploadID", out.UploadID), zap.Int32("parts", partCount))
+
+	return &CreateMultipartUploadResult{
+		Key:             key,
+		UploadID:        out.UploadID,
+		PartSize:        partSize,
+		Parts:           parts,
+		ExpiresIn:       ttl,
+		RequiredHeaders: headers,
+	}, nil
+}
+
+func (h *createMultipartUploadHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "create-multipart-upload-handler"))
+}
+
+// presignParts presigns the given part numbers and returns the URLs, their TTL and the
+// headers the client must send. Each part also needs an x-amz-checksum-sha256 header.
+func presignParts(ctx context.Context, presigner abstraction.Presigner, key, uploadID string, partNumbers []int32) ([]PresignedPart, int, map[string]string, error) {
+	parts := make([]PresignedPart, 0, len(partNumbers))
+	headers := map[string]string{}
+	ttl := 0
+
+	for _, n := range partNumbers {
+		out, err := presigner.PresignUploadPart(ctx, &abstraction.PresignUploadPartInput{
+			Key:        key,
+			UploadID:   uploadID,
+			PartNumber: n,
+		})
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("presign part %d: %w", n, err)
+		}
+
+		parts = append(parts, PresignedPart{PartNumber: n, URL: out.URL})
+		ttl = out.TTLSeconds
+		for name, value := range out.SignedHeaders {
+			headers[name] = value
+		}
+	}
+
+	return parts, ttl, headers, nil
+}
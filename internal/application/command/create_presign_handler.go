@@ -7,6 +7,7 @@ import (
 
 	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
 	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -19,14 +20,23 @@ type CreatePresignCommand struct {
 	OwnerID     string
 	Role        string
 	Size        int64
+	Method      string  // PUT (default) or POST
+	Checksum    *string // SHA-256 of the file, base64 or hex; required for POST
 }
 
+const (
+	UploadMethodPut  = "PUT"
+	UploadMethodPost = "POST"
+)
+
 // CreatePresignResult contains the presigned URL and metadata
 type CreatePresignResult struct {
 	UploadURL       string
 	Key             string
 	ExpiresIn       int
+	Method          string
 	RequiredHeaders map[string]string
+	FormFields      map[string]string // POST only: fields to submit before the file
 }
 
 // CreatePresignCommandHandler handles CreatePresignCommand
@@ -36,40 +46,66 @@ type CreatePresignCommandHandler interface {
 
 type createPresignHandler struct {
 	presigner abstraction.Presigner
+	limits    UploadLimits
 }
 
-func NewCreatePresignHandler(presigner abstraction.Presigner) CreatePresignCommandHandler {
+func NewCreatePresignHandler(presigner abstraction.Presigner, limits UploadLimits) CreatePresignCommandHandler {
 	return &createPresignHandler{
 		presigner: presigner,
+		limits:    limits,
 	}
 }
 
 func (h *createPresignHandler) Handle(ctx context.Context, cmd CreatePresignCommand) (*CreatePresignResult, error) {
 	// Validate content type
-	extByCT := map[string]string{
-		"image/jpeg": ".jpg",
-		"image/png":  ".png",
-		"image/webp": ".webp",
-		"image/avif": ".avif",
-	}
-	ext := extByCT[strings.ToLower(cmd.ContentType)]
+	ext := image.ExtensionForMime(cmd.ContentType)
 	if ext == "" {
 		return nil, fmt.Errorf("unsupported content type: %s", cmd.ContentType)
 	}
 
-	// Get prefix by owner type
-	prefix, err := getPrefixByOwnerType(cmd.OwnerType)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get prefix by owner type: %w", err)
+	// The declared size is signed into the upload, so it must be known up front
+	if cmd.Size <= 0 {
+		return nil, fmt.Errorf("%w: size must be positive", image.ErrInvalidUploadSize)
+	}
+
+	// Reject declared sizes that could never be confirmed
+	maxBytes := h.limits.maxBytesFor(cmd.OwnerType, false)
+	if maxBytes > 0 && cmd.Size > maxBytes {
+		return nil, fmt.Errorf("%w: declared %d bytes, max %d bytes", image.ErrImageTooLarge, cmd.Size, maxBytes)
 	}
 
 	// Generate key
-	key := prefix + cmd.OwnerID + "/" + uuid.New().String() + ext
+	key, err := newObjectKey(cmd.OwnerType, cmd.OwnerID, ext)
+	if err != nil {
+		return nil, err
+	}
+
+	var checksum string
+	if cmd.Checksum != nil {
+		if checksum, err = image.NormalizeChecksum(*cmd.Checksum); err != nil {
+			return nil, err
+		}
+	}
 
-	// Create presigned URL
+	switch cmd.Method {
+	case "", UploadMethodPut:
+		return h.presignPut(ctx, cmd, key, checksum)
+	case UploadMethodPost:
+		if checksum == "" {
+			return nil, fmt.Errorf("%w: checksum is required for POST uploads", image.ErrInvalidChecksum)
+		}
+		return h.presignPost(ctx, cmd, key, checksum)
+	default:
+		return nil, fmt.Errorf("unsupported upload method: %s", cmd.Method)
+	}
+}
+
+func (h *createPresignHandler) presignPut(ctx context.Context, cmd CreatePresignCommand, key, checksum string) (*CreatePresignResult, error) {
 	out, err := h.presigner.PresignPutObject(ctx, &abstraction.PresignPutObjectInput{
-		Key:         key,
-		ContentType: cmd.ContentType,
+		Key:            key,
+		ContentType:    cmd.ContentType,
+		Size:           cmd.Size,
+		ChecksumSHA256: checksum,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("presign put: %w", err)
@@ -77,13 +113,47 @@ func (h *createPresignHandler) Handle(ctx context.Context, cmd CreatePresignComm
 
 	h.log(ctx).Debug("presigned URL created", zap.String("key", key))
 
+	// Without a declared checksum the client must still send x-amz-checksum-sha256
+	// with the base64 SHA-256 of the body
+	requiredHeaders := map[string]string{
+		"Content-Type": cmd.ContentType,
+	}
+	for name, value := range out.SignedHeaders {
+		requiredHeaders[name] = value
+	}
+
+	return &CreatePresignResult{
+		UploadURL:       out.URL,
+		Key:             key,
+		ExpiresIn:       out.TTLSeconds,
+		Method:          UploadMethodPut,
+		RequiredHeaders: requiredHeaders,
+	}, nil
+}
+
+// presignPost creates a POST policy so that S3 itself rejects uploads whose size,
+// type or checksum differ from the declared ones
+func (h *createPresignHandler) presignPost(ctx context.Context, cmd CreatePresignCommand, key, checksum string) (*CreatePresignResult, error) {
+	out, err := h.presigner.PresignPostObject(ctx, &abstraction.PresignPostObjectInput{
+		Key:            key,
+		ContentType:    cmd.ContentType,
+		ChecksumSHA256: checksum,
+		MinSize:        cmd.Size,
+		MaxSize:        cmd.Size,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("presign post: %w", err)
+	}
+
+	h.log(ctx).Debug("presigned POST created", zap.String("key", key), zap.Int64("size", cmd.Size))
+
 	return &CreatePresignResult{
-		UploadURL: out.URL,
-		Key:       key,
-		ExpiresIn: out.TTLSeconds,
-		RequiredHeaders: map[string]string{
-			"Content-Type": cmd.ContentType,
-		},
+		UploadURL:       out.URL,
+		Key:             key,
+		ExpiresIn:       out.TTLSeconds,
+		Method:          UploadMethodPost,
+		RequiredHeaders: map[string]string{},
+		FormFields:      out.Fields,
 	}, nil
 }
 
@@ -103,3 +173,30 @@ func getPrefixByOwnerType(ownerType string) (string, error) {
 		return "", fmt.Errorf("unsupported owner type: %s", ownerType)
 	}
 }
+
+// newObjectKey generates a unique object key under the owner prefix
+func newObjectKey(ownerType, ownerID, ext string) (string, error) {
+	prefix, err := getPrefixByOwnerType(ownerType)
+	if err != nil {
+		return "", fmt.Errorf("failed to get prefix by owner type: %w", err)
+	}
+	return prefix + ownerID + "/" + uuid.New().String() + ext, nil
+}
+
+// ownerPrefixes lists the key prefixes of all supported owner types
+func ownerPrefixes() []string {
+	return []string{"product-drafts/", "products/", "users/"}
+}
+
+// verifyOwnerKey checks that the key was issued for the given owner
+func verifyOwnerKey(ownerType, ownerID, key string) error {
+	prefix, err := getPrefixByOwnerType(ownerType)
+	if err != nil {
+		return fmt.Errorf("failed to get prefix by owner type: %w", err)
+	}
+
+	if ownerID == "" || !strings.HasPrefix(key, prefix+ownerID+"/") {
+		return fmt.Errorf("%w: key does not match expected owner prefix", image.ErrInvalidImageKey)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package command
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// recordingPresigner captures the inputs of the last presign call
+type recordingPresigner struct {
+	abstraction.Presigner
+	put  *abstraction.PresignPutObjectInput
+	post *abstraction.PresignPostObjectInput
+}
+
+func (p *recordingPresigner) PresignPutObject(_ context.Context, input *abstraction.PresignPutObjectInput) (*abstraction.PresignPutObjectOutput, error) {
+	p.put = input
+	return &abstraction.PresignPutObjectOutput{URL: "https://s3/put", TTLSeconds: 60}, nil
+}
+
+func (p *recordingPresigner) PresignPostObject(_ context.Context, input *abstraction.PresignPostObjectInput) (*abstraction.PresignPostObjectOutput, error) {
+	p.post = input
+	return &abstraction.PresignPostObjectOutput{URL: "https://s3/post", Fields: map[string]string{}, TTLSeconds: 60}, nil
+}
+
+func TestCreatePresign(t *testing.T) {
+	sum := sha256.Sum256([]byte("image"))
+	b64 := base64.StdEncoding.EncodeToString(sum[:])
+	hexSum := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name     string
+		cmd      CreatePresignCommand
+		wantErr  error
+		wantPut  *abstraction.PresignPutObjectInput
+		wantPost *abstraction.PresignPostObjectInput
+	}{
+		{
+			name:    "PUT signs the declared size",
+			cmd:     CreatePresignCommand{Size: 1000},
+			wantPut: &abstraction.PresignPutObjectInput{Size: 1000},
+		},
+		{
+			name:    "PUT signs a declared checksum in base64",
+			cmd:     CreatePresignCommand{Size: 1000, Checksum: &hexSum},
+			wantPut: &abstraction.PresignPutObjectInput{Size: 1000, ChecksumSHA256: b64},
+		},
+		{
+			name:     "POST pins size and checksum",
+			cmd:      CreatePresignCommand{Size: 1000, Method: UploadMethodPost, Checksum: &b64},
+			wantPost: &abstraction.PresignPostObjectInput{ChecksumSHA256: b64, MinSize: 1000, MaxSize: 1000},
+		},
+		{
+			name:    "POST without checksum is rejected",
+			cmd:     CreatePresignCommand{Size: 1000, Method: UploadMethodPost},
+			wantErr: image.ErrInvalidChecksum,
+		},
+		{
+			name:    "malformed checksum is rejected",
+			cmd:     CreatePresignCommand{Size: 1000, Checksum: new(string)},
+			wantErr: image.ErrInvalidChecksum,
+		},
+		{
+			name:    "missing size is rejected",
+			cmd:     CreatePresignCommand{},
+			wantErr: image.ErrInvalidUploadSize,
+		},
+		{
+			name:    "size above the limit is rejected",
+			cmd:     CreatePresignCommand{Size: 5000},
+			wantErr: image.ErrImageTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			presigner := &recordingPresigner{}
+			handler := NewCreatePresignHandler(presigner, UploadLimits{MaxBytes: 4096})
+
+			tt.cmd.ContentType = "image/jpeg"
+			tt.cmd.OwnerType = image.OwnerTypeProductDraft
+			tt.cmd.OwnerID = "draft-1"
+
+			result, err := handler.Handle(context.Background(), tt.cmd)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+
+			if tt.wantPut != nil {
+				if presigner.put == nil {
+					t.Fatal("PUT was not presigned")
+				}
+				got := *presigner.put
+				if got.Key != result.Key || got.ContentType != "image/jpeg" ||
+					got.Size != tt.wantPut.Size || got.ChecksumSHA256 != tt.wantPut.ChecksumSHA256 {
+					t.Errorf("PUT input = %+v, want size %d checksum %q", got, tt.wantPut.Size, tt.wantPut.ChecksumSHA256)
+				}
+			}
+			if tt.wantPost != nil {
+				if presigner.post == nil {
+					t.Fatal("POST was not presigned")
+				}
+				got := *presigner.post
+				if got.Key != result.Key || got.ChecksumSHA256 != tt.wantPost.ChecksumSHA256 ||
+					got.MinSize != tt.wantPost.MinSize || got.MaxSize != tt.wantPost.MaxSize {
+					t.Errorf("POST input = %+v, want %+v", got, *tt.wantPost)
+				}
+			}
+		})
+	}
+}
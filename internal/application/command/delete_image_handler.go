@@ -44,30 +44,32 @@ func (h *deleteImageHandler) Handle(ctx context.Context, cmd DeleteImageCommand)
 		return fmt.Errorf("failed to get image: %w", err)
 	}
 
+	// A soft delete keeps the object so that the image can be restored
+	if !cmd.Hard {
+		if img.IsDeleted() {
+			return nil
+		}
+		img.MarkAsDeleted()
+		if _, err := h.repo.Update(ctx, img); err != nil {
+			return fmt.Errorf("failed to mark image as deleted in db: %w", err)
+		}
+		h.log(ctx).Debug("image soft deleted", zap.String("id", cmd.ImageID))
+		return nil
+	}
+
 	// Delete from S3
 	err = h.objStorage.DeleteObject(ctx, &abstraction.DeleteObjectInput{
 		Key: img.Key,
 	})
-
 	if err != nil {
 		h.log(ctx).Warn("failed to delete s3 object (continuing anyway)", zap.Error(err), zap.String("key", img.Key))
 	}
 
 	// Delete from database
-	if cmd.Hard {
-		err := h.repo.Delete(ctx, cmd.ImageID)
-		if err != nil {
-			return fmt.Errorf("failed to delete image from db: %w", err)
-		}
-		h.log(ctx).Debug("image hard deleted", zap.String("id", cmd.ImageID))
-	} else {
-		img.MarkAsDeleted()
-		_, err := h.repo.Update(ctx, img)
-		if err != nil {
-			return fmt.Errorf("failed to mark image as deleted in db: %w", err)
-		}
-		h.log(ctx).Debug("image soft deleted", zap.String("id", cmd.ImageID))
+	if err := h.repo.Delete(ctx, cmd.ImageID); err != nil {
+		return fmt.Errorf("failed to delete image from db: %w", err)
 	}
+	h.log(ctx).Debug("image hard deleted", zap.String("id", cmd.ImageID))
 
 	return nil
 }
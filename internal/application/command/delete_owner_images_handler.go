@@ -0,0 +1,91 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"go.uber.org/zap"
+)
+
+// DeleteOwnerImagesCommand represents a request to delete every image of an owner,
+// e.g. when the product is deleted or the draft is discarded
+type DeleteOwnerImagesCommand struct {
+	OwnerType string
+	OwnerID   string
+}
+
+// DeleteOwnerImagesCommandHandler handles DeleteOwnerImagesCommand
+type DeleteOwnerImagesCommandHandler interface {
+	Handle(ctx context.Context, cmd DeleteOwnerImagesCommand) (int, error)
+}
+
+type deleteOwnerImagesHandler struct {
+	repo       image.Repository
+	objStorage abstraction.ObjectStorage
+	hard       bool
+}
+
+// NewDeleteOwnerImagesHandler creates the handler; hard removes objects and records
+// instead of moving the images to the trash
+func NewDeleteOwnerImagesHandler(repo image.Repository, storage abstraction.ObjectStorage, hard bool) DeleteOwnerImagesCommandHandler {
+	return &deleteOwnerImagesHandler{
+		repo:       repo,
+		objStorage: storage,
+		hard:       hard,
+	}
+}
+
+// Handle deletes the owner's images and returns how many were deleted.
+// Already deleted images are skipped, so a redelivered event changes nothing.
+func (h *deleteOwnerImagesHandler) Handle(ctx context.Context, cmd DeleteOwnerImagesCommand) (int, error) {
+	images, err := h.repo.FindByOwner(ctx, cmd.OwnerType, cmd.OwnerID, nil)
+	if err != nil {
+		return 0, fmt.Errorf("list owner images: %w", err)
+	}
+
+	deleted := 0
+	for _, img := range images {
+		if err := h.delete(ctx, img); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	h.log(ctx).Info("owner images deleted",
+		zap.String("ownerType", cmd.OwnerType),
+		zap.String("ownerId", cmd.OwnerID),
+		zap.Int("count", deleted),
+		zap.Bool("hard", h.hard),
+	)
+
+	return deleted, nil
+}
+
+func (h *deleteOwnerImagesHandler) delete(ctx context.Context, img *image.Image) error {
+	if !h.hard {
+		img.MarkAsDeleted()
+		if _, err := h.repo.Update(ctx, img); err != nil {
+			return fmt.Errorf("mark image %s as deleted: %w", img.ID, err)
+		}
+		return nil
+	}
+
+	err := h.objStorage.DeleteObject(ctx, &abstraction.DeleteObjectInput{
+		Key: img.Key,
+	})
+	if err != nil {
+		h.log(ctx).Warn("failed to delete s3 object (continuing anyway)", zap.Error(err), zap.String("key", img.Key))
+	}
+
+	if err := h.repo.Delete(ctx, img.ID); err != nil {
+		return fmt.Errorf("delete image %s: %w", img.ID, err)
+	}
+	return nil
+}
+
+func (h *deleteOwnerImagesHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "delete-owner-images-handler"))
+}
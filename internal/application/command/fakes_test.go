@@ -0,0 +1,275 @@
+package command
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// fakeImageRepo is an in-memory image.Repository that hands out copies, like a database
+// would. Methods a test does not need panic through the embedded nil interface.
+type fakeImageRepo struct {
+	image.Repository
+
+	mu     sync.Mutex
+	images map[string]*image.Image
+}
+
+func newFakeImageRepo(images ...*image.Image) *fakeImageRepo {
+	r := &fakeImageRepo{images: make(map[string]*image.Image)}
+	for _, img := range images {
+		r.images[img.ID] = clone(img)
+	}
+	return r
+}
+
+func (r *fakeImageRepo) Save(_ context.Context, img *image.Image) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.images[img.ID]; ok {
+		return image.ErrImageAlreadyExists
+	}
+	for _, other := range r.images {
+		if other.Key == img.Key { // unique index on the object key
+			return image.ErrImageAlreadyExists
+		}
+	}
+	r.images[img.ID] = clone(img)
+	return nil
+}
+
+func (r *fakeImageRepo) FindByID(_ context.Context, id string) (*image.Image, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	img, ok := r.images[id]
+	if !ok {
+		return nil, persistence.ErrEntityNotFound
+	}
+	return clone(img), nil
+}
+
+func (r *fakeImageRepo) FindByKey(_ context.Context, key string) (*image.Image, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, img := range r.images {
+		if img.Key == key {
+			return clone(img), nil
+		}
+	}
+	return nil, persistence.ErrEntityNotFound
+}
+
+func (r *fakeImageRepo) FindExistingKeys(_ context.Context, keys []string) (map[string]bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing := make(map[string]bool)
+	for _, img := range r.images {
+		if contains(keys, img.Key) {
+			existing[img.Key] = true
+		}
+	}
+	return existing, nil
+}
+
+func (r *fakeImageRepo) FindByOwner(_ context.Context, ownerType, ownerID string, imageIDs []string) ([]*image.Image, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*image.Image
+	for _, img := range r.sorted() {
+		if img.OwnerType != ownerType || img.OwnerID != ownerID || img.IsDeleted() {
+			continue
+		}
+		if len(imageIDs) > 0 && !contains(imageIDs, img.ID) {
+			continue
+		}
+		out = append(out, clone(img))
+	}
+	return out, nil
+}
+
+func (r *fakeImageRepo) FindDeletedBefore(_ context.Context, ownerType string, before time.Time, limit int) ([]*image.Image, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*image.Image
+	for _, img := range r.sorted() {
+		if img.IsDeleted() && img.OwnerType == ownerType && img.DeletedAt != nil && img.DeletedAt.Before(before) {
+			out = append(out, clone(img))
+		}
+		if len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Search supports the status, owner and limit criteria; the cursor is the ID of the last image
+func (r *fakeImageRepo) Search(_ context.Context, criteria image.SearchCriteria) (*image.SearchResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := &image.SearchResult{}
+	for _, img := range r.sorted() {
+		if criteria.Cursor != "" && img.ID <= criteria.Cursor {
+			continue
+		}
+		if criteria.Status != "" && img.Status != criteria.Status || criteria.Status == "" && img.IsDeleted() {
+			continue
+		}
+		if criteria.OwnerType != "" && img.OwnerType != criteria.OwnerType ||
+			criteria.OwnerID != "" && img.OwnerID != criteria.OwnerID {
+			continue
+		}
+		if len(result.Images) == criteria.Limit {
+			result.NextCursor = result.Images[len(result.Images)-1].ID
+			break
+		}
+		result.Images = append(result.Images, clone(img))
+	}
+	return result, nil
+}
+
+func (r *fakeImageRepo) Update(_ context.Context, img *image.Image) (*image.Image, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.images[img.ID]
+	if !ok {
+		return nil, persistence.ErrEntityNotFound
+	}
+	if current.Version != img.Version {
+		return nil, persistence.ErrOptimisticLocking
+	}
+	updated := clone(img)
+	updated.IncrementVersion()
+	r.images[img.ID] = updated
+	return clone(updated), nil
+}
+
+func (r *fakeImageRepo) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.images[id]; !ok {
+		return persistence.ErrEntityNotFound
+	}
+	delete(r.images, id)
+	return nil
+}
+
+func (r *fakeImageRepo) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.images)
+}
+
+func (r *fakeImageRepo) get(id string) *image.Image {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if img, ok := r.images[id]; ok {
+		return clone(img)
+	}
+	return nil
+}
+
+func (r *fakeImageRepo) sorted() []*image.Image {
+	out := make([]*image.Image, 0, len(r.images))
+	for _, img := range r.images {
+		out = append(out, img)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// fakeStorage is an in-memory abstraction.ObjectStorage keyed by object key
+type fakeStorage struct {
+	abstraction.ObjectStorage
+
+	mu         sync.Mutex
+	objects    map[string][]byte
+	deleted    []string
+	noChecksum bool // store objects like uploads that sent no checksum header
+}
+
+func newFakeStorage() *fakeStorage {
+	return &fakeStorage{objects: make(map[string][]byte)}
+}
+
+func (s *fakeStorage) put(key string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = body
+}
+
+func (s *fakeStorage) has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.objects[key]
+	return ok
+}
+
+// HeadObject reports the SHA-256 checksum S3 stores for uploads that send one
+func (s *fakeStorage) HeadObject(_ context.Context, input *abstraction.HeadObjectInput) (*abstraction.HeadObjectOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, ok := s.objects[input.Key]
+	if !ok {
+		return nil, abstraction.ErrObjectNotFound
+	}
+	size := int64(len(body))
+	if s.noChecksum {
+		return &abstraction.HeadObjectOutput{ContentLength: &size}, nil
+	}
+	sum := sha256.Sum256(body)
+	checksum := base64.StdEncoding.EncodeToString(sum[:])
+	return &abstraction.HeadObjectOutput{ContentLength: &size, ChecksumSHA256: &checksum}, nil
+}
+
+func (s *fakeStorage) GetObjectRange(_ context.Context, input *abstraction.GetObjectRangeInput) (*abstraction.GetObjectRangeOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, ok := s.objects[input.Key]
+	if !ok {
+		return nil, abstraction.ErrObjectNotFound
+	}
+	end := min(input.Offset+input.Length, int64(len(body)))
+	return &abstraction.GetObjectRangeOutput{Body: body[input.Offset:end]}, nil
+}
+
+func (s *fakeStorage) DeleteObject(_ context.Context, input *abstraction.DeleteObjectInput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, input.Key)
+	s.deleted = append(s.deleted, input.Key)
+	return nil
+}
+
+func clone(img *image.Image) *image.Image {
+	c := *img
+	return &c
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestImage(t interface{ Fatalf(string, ...any) }, id, ownerType, ownerID string) *image.Image {
+	img, err := image.NewImageWithID(id, "", ownerType, ownerID, "gallery", ownerPrefix(ownerType)+ownerID+"/"+id+".jpg", "image/jpeg", 100)
+	if err != nil {
+		t.Fatalf("new image: %v", err)
+	}
+	return img
+}
+
+func ownerPrefix(ownerType string) string {
+	prefix, _ := getPrefixByOwnerType(ownerType)
+	return prefix
+}
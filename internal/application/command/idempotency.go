@@ -0,0 +1,16 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// idempotencyNamespace scopes the name-based UUIDs derived for retried requests
+var idempotencyNamespace = uuid.MustParse("6f1c3c1e-8d0a-4c52-9a4b-3f5d2e7a9b10")
+
+// idempotentID derives a stable ID from the parts, so that a retried request
+// addresses the record created by the first attempt
+func idempotentID(parts ...string) string {
+	return uuid.NewSHA1(idempotencyNamespace, []byte(strings.Join(parts, "\x00"))).String()
+}
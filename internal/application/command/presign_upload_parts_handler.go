@@ -0,0 +1,67 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// PresignUploadPartsCommand represents a request to (re)presign parts of a multipart upload,
+// e.g. to resume an interrupted upload after the original URLs expired
+type PresignUploadPartsCommand struct {
+	OwnerType   string
+	OwnerID     string
+	Key         string
+	UploadID    string
+	PartNumbers []int32
+}
+
+// PresignUploadPartsResult contains the presigned part URLs
+type PresignUploadPartsResult struct {
+	Parts           []PresignedPart
+	ExpiresIn       int
+	RequiredHeaders map[string]string
+}
+
+// PresignUploadPartsCommandHandler handles PresignUploadPartsCommand
+type PresignUploadPartsCommandHandler interface {
+	Handle(ctx context.Context, cmd PresignUploadPartsCommand) (*PresignUploadPartsResult, error)
+}
+
+type presignUploadPartsHandler struct {
+	presigner abstraction.Presigner
+}
+
+func NewPresignUploadPartsHandler(presigner abstraction.Presigner) PresignUploadPartsCommandHandler {
+	return &presignUploadPartsHandler{
+		presigner: presigner,
+	}
+}
+
+func (h *presignUploadPartsHandler) Handle(ctx context.Context, cmd PresignUploadPartsCommand) (*PresignUploadPartsResult, error) {
+	if err := verifyOwnerKey(cmd.OwnerType, cmd.OwnerID, cmd.Key); err != nil {
+		return nil, err
+	}
+
+	if cmd.UploadID == "" || len(cmd.PartNumbers) == 0 {
+		return nil, fmt.Errorf("%w: upload ID and part numbers are required", image.ErrInvalidUploadParts)
+	}
+	for _, n := range cmd.PartNumbers {
+		if n < 1 || n > maxPartCount {
+			return nil, fmt.Errorf("%w: part number %d out of range", image.ErrInvalidUploadParts, n)
+		}
+	}
+
+	parts, ttl, headers, err := presignParts(ctx, h.presigner, cmd.Key, cmd.UploadID, cmd.PartNumbers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresignUploadPartsResult{
+		Parts:           parts,
+		ExpiresIn:       ttl,
+		RequiredHeaders: headers,
+	}, nil
+}
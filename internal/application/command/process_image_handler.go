@@ -0,0 +1,133 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/processing"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"go.uber.org/zap"
+)
+
+// ProcessImageCommand represents a request to run the post-upload processing of an image
+type ProcessImageCommand struct {
+	ImageID string
+}
+
+// ProcessImageCommandHandler handles ProcessImageCommand
+type ProcessImageCommandHandler interface {
+	Handle(ctx context.Context, cmd ProcessImageCommand) (*image.Image, error)
+}
+
+type processImageHandler struct {
+	repo        image.Repository
+	pipeline    *processing.Pipeline
+	deleteImage DeleteImageCommandHandler
+	maxAttempts int
+}
+
+// NewProcessImageHandler creates the handler. An image whose processing fails transiently
+// maxAttempts times in a row is marked as failed.
+func NewProcessImageHandler(
+	repo image.Repository,
+	pipeline *processing.Pipeline,
+	deleteImage DeleteImageCommandHandler,
+	maxAttempts int,
+) ProcessImageCommandHandler {
+	return &processImageHandler{
+		repo:        repo,
+		pipeline:    pipeline,
+		deleteImage: deleteImage,
+		maxAttempts: maxAttempts,
+	}
+}
+
+func (h *processImageHandler) Handle(ctx context.Context, cmd ProcessImageCommand) (*image.Image, error) {
+	img, err := h.repo.FindByID(ctx, cmd.ImageID)
+	if err != nil {
+		if errors.Is(err, persistence.ErrEntityNotFound) {
+			return nil, image.ErrImageNotFound
+		}
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	if img.IsDeleted() {
+		return nil, image.ErrImageAlreadyDeleted
+	}
+
+	if img.Status != image.StatusProcessing {
+		img.MarkAsProcessing()
+		if img, err = h.repo.Update(ctx, img); err != nil {
+			return nil, fmt.Errorf("failed to mark image as processing: %w", err)
+		}
+	}
+
+	if err := h.pipeline.Run(ctx, img); err != nil {
+		switch {
+		case errors.Is(err, image.ErrImageRejected):
+			h.log(ctx).Info("image rejected by processing", zap.String("id", img.ID), zap.Error(err))
+			img.MarkAsFailed(err.Error())
+		case errors.Is(err, abstraction.ErrObjectNotFound):
+			h.log(ctx).Warn("image object is missing", zap.String("id", img.ID), zap.String("key", img.Key))
+			img.MarkAsFailed("stored object not found")
+		default:
+			// Transient errors leave the image in processing so it can be retried, up to the limit
+			attempts := img.RecordFailedProcessingAttempt()
+			if attempts < h.maxAttempts {
+				if _, updateErr := h.repo.Update(ctx, img); updateErr != nil {
+					h.log(ctx).Warn("failed to record processing attempt", zap.Error(updateErr), zap.String("id", img.ID))
+				}
+				return nil, fmt.Errorf("failed to process image: %w", err)
+			}
+			h.log(ctx).Warn("image processing gave up", zap.String("id", img.ID), zap.Int("attempts", attempts), zap.Error(err))
+			img.MarkAsFailed(fmt.Sprintf("processing failed %d times: %v", attempts, err))
+		}
+	} else {
+		img.MarkAsReady()
+	}
+
+	updated, err := h.repo.Update(ctx, img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save processed image: %w", err)
+	}
+
+	h.log(ctx).Debug("image processed", zap.String("id", updated.ID), zap.String("status", string(updated.Status)))
+
+	// A user has a single avatar per role. A new one replaces the previous ones once it is
+	// ready, so a failed upload leaves the user with the avatar they had.
+	if updated.Status == image.StatusReady && updated.OwnerType == image.OwnerTypeUser {
+		h.replacePrevious(ctx, updated)
+	}
+
+	return updated, nil
+}
+
+// replacePrevious deletes the owner's older images with the same role.
+// Failures are logged only: the new image is already ready and is the current one.
+func (h *processImageHandler) replacePrevious(ctx context.Context, img *image.Image) {
+	existing, err := h.repo.FindByOwner(ctx, img.OwnerType, img.OwnerID, nil)
+	if err != nil {
+		h.log(ctx).Warn("failed to find previous images", zap.Error(err), zap.String("ownerId", img.OwnerID))
+		return
+	}
+
+	for _, prev := range existing {
+		// A newer upload that became ready first has already replaced this one
+		if prev.ID == img.ID || prev.Role != img.Role || prev.IsDeleted() || !prev.CreatedAt.Before(img.CreatedAt) {
+			continue
+		}
+		if err := h.deleteImage.Handle(ctx, DeleteImageCommand{ImageID: prev.ID}); err != nil {
+			h.log(ctx).Warn("failed to delete replaced image", zap.Error(err), zap.String("id", prev.ID))
+			continue
+		}
+		h.log(ctx).Debug("replaced previous image", zap.String("id", prev.ID), zap.String("replacedBy", img.ID))
+	}
+}
+
+func (h *processImageHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "process-image-handler"))
+}
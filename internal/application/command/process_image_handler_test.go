@@ -0,0 +1,118 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/processing"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// failingStep fails every run with the error
+type failingStep struct {
+	err error
+}
+
+func (s failingStep) Name() string { return "failing" }
+
+func (s failingStep) Run(context.Context, *image.Image) error { return s.err }
+
+func TestProcessImageFailures(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		runs         int
+		wantStatus   image.ImageStatus
+		wantAttempts int
+	}{
+		{"transient error is retried", errors.New("imgproxy unavailable"), 2, image.StatusProcessing, 2},
+		{"transient error gives up at the limit", errors.New("imgproxy unavailable"), 3, image.StatusFailed, 3},
+		{"missing object fails at once", fmt.Errorf("head object: %w", abstraction.ErrObjectNotFound), 1, image.StatusFailed, 0},
+		{"rejection fails at once", fmt.Errorf("%w: too small", image.ErrImageRejected), 1, image.StatusFailed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := newTestImage(t, "img-1", image.OwnerTypeProduct, "p-1")
+			img.MarkAsProcessing()
+			repo := newFakeImageRepo(img)
+			handler := NewProcessImageHandler(repo, processing.NewPipeline(failingStep{err: tt.err}), nil, 3)
+
+			for range tt.runs {
+				_, _ = handler.Handle(context.Background(), ProcessImageCommand{ImageID: "img-1"})
+			}
+
+			stored := repo.get("img-1")
+			if stored.Status != tt.wantStatus || stored.ProcessingAttempts != tt.wantAttempts {
+				t.Fatalf("image is %s after %d attempts, want %s after %d", stored.Status, stored.ProcessingAttempts, tt.wantStatus, tt.wantAttempts)
+			}
+			if stored.Status == image.StatusFailed && stored.FailureReason == "" {
+				t.Fatal("failed image has no reason")
+			}
+		})
+	}
+}
+
+func TestProcessImageRequeueResetsAttempts(t *testing.T) {
+	img := newTestImage(t, "img-1", image.OwnerTypeProduct, "p-1")
+	img.MarkAsProcessing()
+	img.RecordFailedProcessingAttempt()
+	img.MarkAsFailed("gave up")
+	repo := newFakeImageRepo(img)
+
+	// A manual run queues the failed image again with a fresh count
+	handler := NewProcessImageHandler(repo, processing.NewPipeline(), nil, 3)
+	processed, err := handler.Handle(context.Background(), ProcessImageCommand{ImageID: "img-1"})
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if processed.Status != image.StatusReady || processed.ProcessingAttempts != 0 {
+		t.Fatalf("image is %s after %d attempts, want ready", processed.Status, processed.ProcessingAttempts)
+	}
+}
+
+func TestProcessImageReplacesPreviousAvatar(t *testing.T) {
+	tests := []struct {
+		name        string
+		processed   string
+		err         error
+		wantDeleted string
+		wantKept    string
+	}{
+		{"ready avatar replaces the older one", "new", nil, "old", "new"},
+		{"failed avatar keeps the older one", "new", fmt.Errorf("%w: too small", image.ErrImageRejected), "", "old"},
+		{"older avatar ready late keeps the newer one", "old", nil, "", "new"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := newTestImage(t, "old", image.OwnerTypeUser, "u-1")
+			old.CreatedAt = old.CreatedAt.Add(-time.Hour)
+			old.MarkAsProcessing()
+			newer := newTestImage(t, "new", image.OwnerTypeUser, "u-1")
+			newer.MarkAsProcessing()
+			repo := newFakeImageRepo(old, newer)
+			storage := newFakeStorage()
+
+			var steps []processing.Step
+			if tt.err != nil {
+				steps = append(steps, failingStep{err: tt.err})
+			}
+			handler := NewProcessImageHandler(repo, processing.NewPipeline(steps...), NewDeleteImageHandler(repo, storage), 3)
+			if _, err := handler.Handle(context.Background(), ProcessImageCommand{ImageID: tt.processed}); err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+
+			if tt.wantDeleted != "" && !repo.get(tt.wantDeleted).IsDeleted() {
+				t.Errorf("image %s was not replaced", tt.wantDeleted)
+			}
+			if repo.get(tt.wantKept).IsDeleted() {
+				t.Errorf("image %s was deleted", tt.wantKept)
+			}
+		})
+	}
+}
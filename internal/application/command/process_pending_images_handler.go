@@ -0,0 +1,85 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"go.uber.org/zap"
+)
+
+// processBatchSize is how many images waiting for processing are looked up at a time
+const processBatchSize = 50
+
+// ProcessPendingImagesCommand represents a request to process all images waiting in processing,
+// oldest first. Confirmed and promoted images are left there instead of being processed inline.
+type ProcessPendingImagesCommand struct{}
+
+// ProcessPendingImagesResult counts the images handled by a run
+type ProcessPendingImagesResult struct {
+	Processed int // ready or permanently failed
+	Retried   int // left in processing after a transient error
+}
+
+// ProcessPendingImagesCommandHandler handles ProcessPendingImagesCommand
+type ProcessPendingImagesCommandHandler interface {
+	Handle(ctx context.Context, cmd ProcessPendingImagesCommand) (*ProcessPendingImagesResult, error)
+}
+
+type processPendingImagesHandler struct {
+	repo    image.Repository
+	process ProcessImageCommandHandler
+}
+
+func NewProcessPendingImagesHandler(repo image.Repository, process ProcessImageCommandHandler) ProcessPendingImagesCommandHandler {
+	return &processPendingImagesHandler{
+		repo:    repo,
+		process: process,
+	}
+}
+
+func (h *processPendingImagesHandler) Handle(ctx context.Context, cmd ProcessPendingImagesCommand) (*ProcessPendingImagesResult, error) {
+	result := &ProcessPendingImagesResult{}
+
+	// Images that stay in processing after a transient error are passed by the cursor,
+	// so that they are retried on the next run instead of blocking the others
+	criteria := image.SearchCriteria{
+		Status: image.StatusProcessing,
+		Sort:   image.SortCreatedAtAsc,
+		Limit:  processBatchSize,
+	}
+	for {
+		page, err := h.repo.Search(ctx, criteria)
+		if err != nil {
+			return result, fmt.Errorf("find images in processing: %w", err)
+		}
+
+		for _, img := range page.Images {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			if _, err := h.process.Handle(ctx, ProcessImageCommand{ImageID: img.ID}); err != nil {
+				h.log(ctx).Warn("failed to process image", zap.Error(err), zap.String("id", img.ID))
+				result.Retried++
+				continue
+			}
+			result.Processed++
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		criteria.Cursor = page.NextCursor
+	}
+
+	if result.Processed > 0 || result.Retried > 0 {
+		h.log(ctx).Info("pending images processed", zap.Int("processed", result.Processed), zap.Int("retried", result.Retried))
+	}
+
+	return result, nil
+}
+
+func (h *processPendingImagesHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "process-pending-images-handler"))
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// fakeProcessor records the images it is asked to process and fails for some of them
+type fakeProcessor struct {
+	fail  map[string]bool
+	calls map[string]int
+}
+
+func (p *fakeProcessor) Handle(_ context.Context, cmd ProcessImageCommand) (*image.Image, error) {
+	p.calls[cmd.ImageID]++
+	if p.fail[cmd.ImageID] {
+		return nil, errors.New("imgproxy unavailable")
+	}
+	return nil, nil
+}
+
+func TestProcessPendingImages(t *testing.T) {
+	var images []*image.Image
+	for i := range processBatchSize + 10 {
+		img := newTestImage(t, fmt.Sprintf("img-%03d", i), image.OwnerTypeProduct, "p-1")
+		img.MarkAsProcessing()
+		images = append(images, img)
+	}
+	ready := newTestImage(t, "ready", image.OwnerTypeProduct, "p-1")
+	ready.MarkAsReady()
+	draft := newTestImage(t, "draft", image.OwnerTypeProductDraft, "d-1")
+	images = append(images, ready, draft)
+
+	processor := &fakeProcessor{
+		fail:  map[string]bool{"img-000": true},
+		calls: make(map[string]int),
+	}
+	handler := NewProcessPendingImagesHandler(newFakeImageRepo(images...), processor)
+
+	result, err := handler.Handle(context.Background(), ProcessPendingImagesCommand{})
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	if result.Processed != processBatchSize+9 || result.Retried != 1 {
+		t.Errorf("result = %+v, want %d processed and 1 retried", *result, processBatchSize+9)
+	}
+	for i := range processBatchSize + 10 {
+		id := fmt.Sprintf("img-%03d", i)
+		if processor.calls[id] != 1 {
+			t.Errorf("%s processed %d times, want once", id, processor.calls[id])
+		}
+	}
+	for _, id := range []string{"ready", "draft"} {
+		if processor.calls[id] != 0 {
+			t.Errorf("%s is not in processing but was processed", id)
+		}
+	}
+}
@@ -2,12 +2,12 @@ package command
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
-	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
 	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/promotion"
 	"go.uber.org/zap"
 )
 
@@ -16,6 +16,9 @@ type PromoteImagesCommand struct {
 	DraftID   string
 	ImageIDs  *[]string
 	ProductID string
+
+	// IdempotencyKey identifies retries of the same promotion
+	IdempotencyKey string
 }
 
 // PromoteImagesCommandHandler handles PromoteImagesCommand
@@ -24,78 +27,45 @@ type PromoteImagesCommandHandler interface {
 }
 
 type promoteImagesHandler struct {
-	repo       image.Repository
-	objStorage abstraction.ObjectStorage
+	repo   image.Repository
+	runner PromotionRunner
 }
 
-func NewPromoteImagesHandler(repo image.Repository, storage abstraction.ObjectStorage) PromoteImagesCommandHandler {
+func NewPromoteImagesHandler(repo image.Repository, runner PromotionRunner) PromoteImagesCommandHandler {
 	return &promoteImagesHandler{
-		repo:       repo,
-		objStorage: storage,
+		repo:   repo,
+		runner: runner,
 	}
 }
 
+// Handle runs the promotion jobs of the draft to completion, resuming unfinished ones first.
+// Repeating a promotion that already completed returns the promoted images again.
 func (h *promoteImagesHandler) Handle(ctx context.Context, cmd PromoteImagesCommand) ([]*image.Image, error) {
-	var imageIDs []string
-	if cmd.ImageIDs != nil && len(*cmd.ImageIDs) > 0 {
-		imageIDs = *cmd.ImageIDs
+	jobs, err := h.runner.Plan(ctx, cmd)
+	if errors.Is(err, promotion.ErrNothingToDo) {
+		return h.alreadyPromoted(ctx, cmd, err)
 	}
-
-	// Get images from repository
-	images, err := h.repo.FindByOwner(ctx, "productDraft", cmd.DraftID, imageIDs)
 	if err != nil {
-		return nil, fmt.Errorf("list draft images: %w", err)
-	}
-
-	if len(images) == 0 {
-		return []*image.Image{}, fmt.Errorf("no images found for draft %s", cmd.DraftID)
+		return []*image.Image{}, err
 	}
 
 	var promoted []*image.Image
-	srcPrefix := "product-drafts/" + cmd.DraftID + "/"
-
-	for _, img := range images {
-		if !strings.HasPrefix(img.Key, srcPrefix) {
-			return nil, fmt.Errorf("image %s has key outside draft prefix: %s", img.ID, img.Key)
-		}
-
-		// Determine new key
-		dstKey := "products/" + cmd.ProductID + "/" + strings.TrimPrefix(img.Key, srcPrefix)
-
-		// Check if target already exists
-		exists, err := h.objectExists(ctx, dstKey)
-		if err != nil {
-			return nil, fmt.Errorf("check target exists: %w", err)
-		}
-
-		// Copy object if doesn't exist
-		if !exists {
-			err = h.objStorage.CopyObject(ctx, &abstraction.CopyObjectInput{
-				SourceKey: img.Key,
-				TargetKey: dstKey,
-			})
+	for _, job := range jobs {
+		// A completed job is a replay of an earlier attempt
+		if job.Status == promotion.StatusCompleted {
+			images, err := h.jobImages(ctx, job)
 			if err != nil {
-				return nil, fmt.Errorf("copy %s -> %s: %w", img.Key, dstKey, err)
+				return nil, err
 			}
+			promoted = append(promoted, images...)
+			continue
 		}
 
-		// Delete old object
-		_ = h.objStorage.DeleteObject(ctx, &abstraction.DeleteObjectInput{
-			Key: img.Key,
-		})
-
-		// Update domain object
-		if err := img.PromoteToProduct(cmd.ProductID, dstKey); err != nil {
-			return nil, fmt.Errorf("promote image: %w", err)
-		}
-
-		// Save updated image
-		updated, err := h.repo.Update(ctx, img)
+		images, err := h.runner.Run(ctx, job)
 		if err != nil {
-			return nil, fmt.Errorf("update image after promote: %w", err)
+			return nil, fmt.Errorf("run promotion job: %w", err)
 		}
-
-		promoted = append(promoted, updated)
+		promoted = append(promoted, images...)
 	}
 
 	h.log(ctx).Debug("images promoted", zap.Int("count", len(promoted)), zap.String("productID", cmd.ProductID))
@@ -103,16 +73,37 @@ func (h *promoteImagesHandler) Handle(ctx context.Context, cmd PromoteImagesComm
 	return promoted, nil
 }
 
-func (h *promoteImagesHandler) objectExists(ctx context.Context, key string) (bool, error) {
-	_, err := h.objStorage.HeadObject(ctx, &abstraction.HeadObjectInput{
-		Key: key,
-	})
+// alreadyPromoted returns the requested images that are already on the product,
+// or the planning error if there are none
+func (h *promoteImagesHandler) alreadyPromoted(ctx context.Context, cmd PromoteImagesCommand, planErr error) ([]*image.Image, error) {
+	var imageIDs []string
+	if cmd.ImageIDs != nil && len(*cmd.ImageIDs) > 0 {
+		imageIDs = *cmd.ImageIDs
+	}
+
+	images, err := h.repo.FindByOwner(ctx, image.OwnerTypeProduct, cmd.ProductID, imageIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list product images: %w", err)
+	}
+	if len(images) == 0 {
+		return []*image.Image{}, planErr
+	}
+
+	h.log(ctx).Debug("promotion replayed", zap.Int("count", len(images)), zap.String("productID", cmd.ProductID))
+	return images, nil
+}
+
+func (h *promoteImagesHandler) jobImages(ctx context.Context, job *promotion.Job) ([]*image.Image, error) {
+	ids := make([]string, 0, len(job.Items))
+	for _, item := range job.Items {
+		ids = append(ids, item.ImageID)
+	}
+
+	images, err := h.repo.FindByOwner(ctx, image.OwnerTypeProduct, job.ProductID, ids)
 	if err != nil {
-		// Assuming any error means object doesn't exist
-		// Infrastructure layer should handle S3-specific errors
-		return false, nil
+		return nil, fmt.Errorf("list promoted images: %w", err)
 	}
-	return true, nil
+	return images, nil
 }
 
 func (h *promoteImagesHandler) log(ctx context.Context) *zap.Logger {
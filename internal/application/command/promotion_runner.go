@@ -0,0 +1,368 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/promotion"
+	"go.uber.org/zap"
+)
+
+// PromotionRunner plans and executes promotion jobs.
+// Every step is idempotent and the job is stored after each one, so a job interrupted
+// at any point can be run again from its last completed step.
+type PromotionRunner interface {
+	// Plan returns the jobs that promote the requested draft images: unfinished jobs of the
+	// draft, reset to pending, followed by a new job for images no other job covers.
+	// A request repeated with its idempotency key gets the job of the first attempt, even
+	// if completed. promotion.ErrNothingToDo is returned when no draft image is left.
+	Plan(ctx context.Context, cmd PromoteImagesCommand) ([]*promotion.Job, error)
+
+	// Run executes a pending job and returns the images it promoted.
+	// Failed items are recorded on the job and reported as promotion.ErrJobFailed.
+	Run(ctx context.Context, job *promotion.Job) ([]*image.Image, error)
+}
+
+type promotionRunner struct {
+	repo       image.Repository
+	jobs       promotion.Repository
+	objStorage abstraction.ObjectStorage
+}
+
+func NewPromotionRunner(repo image.Repository, jobs promotion.Repository, storage abstraction.ObjectStorage) PromotionRunner {
+	return &promotionRunner{
+		repo:       repo,
+		jobs:       jobs,
+		objStorage: storage,
+	}
+}
+
+func (r *promotionRunner) Plan(ctx context.Context, cmd PromoteImagesCommand) ([]*promotion.Job, error) {
+	// A retried request gets the job of the first attempt, whatever its status
+	jobID := ""
+	if cmd.IdempotencyKey != "" {
+		jobID = idempotentID("promote", cmd.DraftID, cmd.ProductID, cmd.IdempotencyKey)
+		job, err := r.jobs.FindByID(ctx, jobID)
+		if err == nil && job.Status == promotion.StatusCompleted {
+			return []*promotion.Job{job}, nil
+		}
+		if err != nil && !errors.Is(err, persistence.ErrEntityNotFound) {
+			return nil, fmt.Errorf("find promotion job: %w", err)
+		}
+	}
+
+	unfinished, err := r.jobs.FindByDraft(ctx, cmd.DraftID)
+	if err != nil {
+		return nil, fmt.Errorf("list unfinished promotion jobs: %w", err)
+	}
+
+	// Images of unfinished jobs are resumed by those jobs; planning them again
+	// could copy from a source that is already deleted
+	var planned []*promotion.Job
+	claimed := make(map[string]bool)
+	for _, job := range unfinished {
+		if job.Status == promotion.StatusFailed {
+			if err := job.Retry(); err != nil {
+				return nil, err
+			}
+			if err := r.persist(ctx, job); err != nil {
+				return nil, err
+			}
+		}
+		for _, item := range job.Items {
+			if !item.Done() {
+				claimed[item.ImageID] = true
+			}
+		}
+		planned = append(planned, job)
+	}
+
+	var imageIDs []string
+	if cmd.ImageIDs != nil && len(*cmd.ImageIDs) > 0 {
+		imageIDs = *cmd.ImageIDs
+	}
+
+	images, err := r.repo.FindByOwner(ctx, image.OwnerTypeProductDraft, cmd.DraftID, imageIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list draft images: %w", err)
+	}
+
+	srcPrefix := "product-drafts/" + cmd.DraftID + "/"
+	var items []promotion.Item
+	for _, img := range images {
+		if claimed[img.ID] {
+			continue
+		}
+		if !strings.HasPrefix(img.Key, srcPrefix) {
+			return nil, fmt.Errorf("image %s has key outside draft prefix: %s", img.ID, img.Key)
+		}
+		items = append(items, promotion.Item{
+			ImageID:   img.ID,
+			SourceKey: img.Key,
+			TargetKey: "products/" + cmd.ProductID + "/" + strings.TrimPrefix(img.Key, srcPrefix),
+		})
+	}
+
+	if len(items) > 0 {
+		job, err := r.create(ctx, jobID, cmd, items)
+		if err != nil {
+			return nil, err
+		}
+		planned = append(planned, job)
+	}
+
+	if len(planned) == 0 {
+		return nil, fmt.Errorf("%w: draft %s", promotion.ErrNothingToDo, cmd.DraftID)
+	}
+
+	return planned, nil
+}
+
+// create saves a new job. A concurrent attempt of the same request may have saved it first;
+// its job is returned then.
+func (r *promotionRunner) create(ctx context.Context, jobID string, cmd PromoteImagesCommand, items []promotion.Item) (*promotion.Job, error) {
+	job := promotion.NewJob(cmd.DraftID, cmd.ProductID, items)
+	if jobID != "" {
+		job = promotion.NewJobWithID(jobID, cmd.DraftID, cmd.ProductID, items)
+	}
+
+	err := r.jobs.Save(ctx, job)
+	if errors.Is(err, promotion.ErrJobExists) {
+		return r.jobs.FindByID(ctx, job.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("save promotion job: %w", err)
+	}
+	return job, nil
+}
+
+func (r *promotionRunner) Run(ctx context.Context, job *promotion.Job) ([]*image.Image, error) {
+	// Storing the start also claims the job: a concurrent run fails on the version check
+	if err := job.Start(); err != nil {
+		return nil, err
+	}
+	if err := r.persist(ctx, job); err != nil {
+		return nil, err
+	}
+
+	var promoted []*image.Image
+	for i := range job.Items {
+		if job.Items[i].Done() {
+			continue
+		}
+
+		img, err := r.advance(ctx, job, job.Items[i].ImageID)
+		if err != nil {
+			// A lost version check means another run owns the job now
+			if errors.Is(err, errJobPersist) {
+				return nil, err
+			}
+			r.log(ctx).Warn("promotion step failed",
+				zap.Error(err),
+				zap.String("jobId", job.ID),
+				zap.String("imageId", job.Items[i].ImageID),
+				zap.String("step", string(job.Items[i].Step)),
+			)
+			job.FailItem(job.Items[i].ImageID, err.Error())
+			continue
+		}
+		promoted = append(promoted, img)
+	}
+
+	job.Finish()
+	if err := r.persist(ctx, job); err != nil {
+		return nil, err
+	}
+
+	r.log(ctx).Debug("promotion job finished",
+		zap.String("jobId", job.ID),
+		zap.String("status", string(job.Status)),
+		zap.Int("promoted", len(promoted)),
+	)
+
+	if job.Status == promotion.StatusFailed {
+		return promoted, fmt.Errorf("%w: %s: %s", promotion.ErrJobFailed, job.ID, job.LastError)
+	}
+	return promoted, nil
+}
+
+var errJobPersist = errors.New("store promotion job")
+
+// advance takes the item through its remaining steps, storing the job after each one
+func (r *promotionRunner) advance(ctx context.Context, job *promotion.Job, imageID string) (*image.Image, error) {
+	img, err := r.repo.FindByID(ctx, imageID)
+	if err != nil {
+		return nil, fmt.Errorf("get image: %w", err)
+	}
+
+	for {
+		item := job.Item(imageID)
+		var next promotion.Step
+
+		switch item.Step {
+		case promotion.StepPending:
+			if err := r.copy(ctx, img, item); err != nil {
+				return nil, err
+			}
+			next = promotion.StepCopied
+
+		case promotion.StepCopied:
+			err := r.objStorage.DeleteObject(ctx, &abstraction.DeleteObjectInput{
+				Key: item.SourceKey,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("delete source %s: %w", item.SourceKey, err)
+			}
+			next = promotion.StepSourceDeleted
+
+		case promotion.StepSourceDeleted:
+			updated, err := r.updateRecord(ctx, img, job.ProductID, item)
+			if err != nil {
+				return nil, err
+			}
+			img = updated
+			next = promotion.StepRecordUpdated
+
+		default:
+			// The promoted image is left in processing for the processing worker
+			return img, nil
+		}
+
+		job.CompleteStep(imageID, next)
+		if err := r.persist(ctx, job); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// copy copies the source object unless an earlier run already did, and verifies the copy.
+// The source is never deleted unless the copy is byte-identical.
+func (r *promotionRunner) copy(ctx context.Context, img *image.Image, item promotion.Item) error {
+	exists, err := r.objectExists(ctx, item.TargetKey)
+	if err != nil {
+		return fmt.Errorf("check target exists: %w", err)
+	}
+
+	if !exists {
+		err = r.objStorage.CopyObject(ctx, &abstraction.CopyObjectInput{
+			SourceKey: item.SourceKey,
+			TargetKey: item.TargetKey,
+		})
+		if err != nil {
+			return fmt.Errorf("copy %s -> %s: %w", item.SourceKey, item.TargetKey, err)
+		}
+	}
+
+	if err := r.verifyCopy(ctx, img, item); err != nil {
+		return fmt.Errorf("verify copy %s -> %s: %w", item.SourceKey, item.TargetKey, err)
+	}
+	return nil
+}
+
+// updateRecord points the image at the target key. An image already promoted by an
+// interrupted run is left as it is.
+func (r *promotionRunner) updateRecord(ctx context.Context, img *image.Image, productID string, item promotion.Item) (*image.Image, error) {
+	if img.OwnerType == image.OwnerTypeProduct && img.Key == item.TargetKey {
+		return img, nil
+	}
+
+	if err := img.PromoteToProduct(productID, item.TargetKey); err != nil {
+		return nil, fmt.Errorf("promote image: %w", err)
+	}
+
+	// A copy of a multipart object gets a whole-object checksum
+	if image.IsCompositeChecksum(img.Checksum) {
+		dst, err := r.objStorage.HeadObject(ctx, &abstraction.HeadObjectInput{
+			Key: item.TargetKey,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("head target: %w", err)
+		}
+		if dst.ChecksumSHA256 != nil {
+			img.RecordChecksum(*dst.ChecksumSHA256)
+		}
+	}
+
+	updated, err := r.repo.Update(ctx, img)
+	if err != nil {
+		return nil, fmt.Errorf("update image after promote: %w", err)
+	}
+	return updated, nil
+}
+
+// objectExists reports whether an object is stored under the key; errors other than a missing
+// object are returned, so that an unreachable storage is never taken for a missing target
+func (r *promotionRunner) objectExists(ctx context.Context, key string) (bool, error) {
+	_, err := r.objStorage.HeadObject(ctx, &abstraction.HeadObjectInput{
+		Key: key,
+	})
+	if errors.Is(err, abstraction.ErrObjectNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// verifyCopy checks that the target object has the checksum recorded for the image,
+// falling back to the source object checksum for images confirmed without one
+func (r *promotionRunner) verifyCopy(ctx context.Context, img *image.Image, item promotion.Item) error {
+	expected := img.Checksum
+	if expected == "" {
+		src, err := r.objStorage.HeadObject(ctx, &abstraction.HeadObjectInput{
+			Key: item.SourceKey,
+		})
+		if err != nil {
+			return fmt.Errorf("head source: %w", err)
+		}
+		if src.ChecksumSHA256 == nil {
+			return fmt.Errorf("%w: source has no checksum", image.ErrChecksumMismatch)
+		}
+		expected = *src.ChecksumSHA256
+	}
+
+	dst, err := r.objStorage.HeadObject(ctx, &abstraction.HeadObjectInput{
+		Key: item.TargetKey,
+	})
+	if err != nil {
+		return fmt.Errorf("head target: %w", err)
+	}
+	if dst.ChecksumSHA256 == nil {
+		return fmt.Errorf("%w: target has no checksum", image.ErrChecksumMismatch)
+	}
+
+	// A copy of a multipart object gets a whole-object checksum, so only the size can be compared
+	if image.IsCompositeChecksum(expected) {
+		if dst.ContentLength == nil || *dst.ContentLength != img.Size {
+			return fmt.Errorf("%w: target size differs from source", image.ErrChecksumMismatch)
+		}
+		return nil
+	}
+
+	if *dst.ChecksumSHA256 != expected {
+		return fmt.Errorf("%w: expected %s", image.ErrChecksumMismatch, expected)
+	}
+
+	return nil
+}
+
+// persist stores the job and takes over the stored version
+func (r *promotionRunner) persist(ctx context.Context, job *promotion.Job) error {
+	updated, err := r.jobs.Update(ctx, job)
+	if err != nil {
+		return fmt.Errorf("%w %s: %w", errJobPersist, job.ID, err)
+	}
+	*job = *updated
+	return nil
+}
+
+func (r *promotionRunner) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "promotion-runner"))
+}
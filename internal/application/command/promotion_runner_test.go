@@ -0,0 +1,50 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+)
+
+// unreachableStorage fails every request like a storage that cannot be reached
+type unreachableStorage struct {
+	abstraction.ObjectStorage
+}
+
+var errUnreachable = errors.New("connection refused")
+
+func (unreachableStorage) HeadObject(context.Context, *abstraction.HeadObjectInput) (*abstraction.HeadObjectOutput, error) {
+	return nil, errUnreachable
+}
+
+func TestObjectExists(t *testing.T) {
+	storage := newFakeStorage()
+	storage.put("products/p-1/a.jpg", []byte("a"))
+
+	tests := []struct {
+		name       string
+		storage    abstraction.ObjectStorage
+		key        string
+		wantExists bool
+		wantErr    error
+	}{
+		{"stored object", storage, "products/p-1/a.jpg", true, nil},
+		{"missing object", storage, "products/p-1/b.jpg", false, nil},
+		{"storage error is not a missing object", unreachableStorage{}, "products/p-1/a.jpg", false, errUnreachable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &promotionRunner{objStorage: tt.storage}
+			exists, err := r.objectExists(context.Background(), tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if exists != tt.wantExists {
+				t.Errorf("exists = %v, want %v", exists, tt.wantExists)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"go.uber.org/zap"
+)
+
+// purgeBatchSize is how many deleted images are looked up at a time
+const purgeBatchSize = 100
+
+// PurgeDeletedImagesCommand represents a request to hard delete images that have been
+// in the trash longer than the retention of their owner type
+type PurgeDeletedImagesCommand struct {
+	Retention map[string]time.Duration // by owner type; owner types not listed are skipped
+}
+
+// PurgeDeletedImagesCommandHandler handles PurgeDeletedImagesCommand
+type PurgeDeletedImagesCommandHandler interface {
+	Handle(ctx context.Context, cmd PurgeDeletedImagesCommand) (int, error)
+}
+
+type purgeDeletedImagesHandler struct {
+	repo        image.Repository
+	deleteImage DeleteImageCommandHandler
+}
+
+func NewPurgeDeletedImagesHandler(repo image.Repository, deleteImage DeleteImageCommandHandler) PurgeDeletedImagesCommandHandler {
+	return &purgeDeletedImagesHandler{
+		repo:        repo,
+		deleteImage: deleteImage,
+	}
+}
+
+func (h *purgeDeletedImagesHandler) Handle(ctx context.Context, cmd PurgeDeletedImagesCommand) (int, error) {
+	purged := 0
+
+	for ownerType, retention := range cmd.Retention {
+		cutoff := time.Now().Add(-retention)
+
+		for {
+			images, err := h.repo.FindDeletedBefore(ctx, ownerType, cutoff, purgeBatchSize)
+			if err != nil {
+				return purged, fmt.Errorf("find deleted %s images: %w", ownerType, err)
+			}
+
+			failed := 0
+			for _, img := range images {
+				if err := h.deleteImage.Handle(ctx, DeleteImageCommand{ImageID: img.ID, Hard: true}); err != nil {
+					h.log(ctx).Warn("failed to purge deleted image", zap.Error(err), zap.String("id", img.ID))
+					failed++
+					continue
+				}
+				purged++
+			}
+
+			// Stop on the last batch, or when nothing in the batch could be purged to avoid looping on it
+			if len(images) < purgeBatchSize || failed == len(images) {
+				break
+			}
+		}
+	}
+
+	if purged > 0 {
+		h.log(ctx).Info("deleted images purged", zap.Int("count", purged))
+	}
+
+	return purged, nil
+}
+
+func (h *purgeDeletedImagesHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "purge-deleted-images-handler"))
+}
@@ -0,0 +1,86 @@
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+func deletedImage(t *testing.T, id, ownerType string, deletedAgo time.Duration) *image.Image {
+	img := newTestImage(t, id, ownerType, "owner-1")
+	img.MarkAsDeleted()
+	deletedAt := time.Now().Add(-deletedAgo).UTC()
+	img.DeletedAt = &deletedAt
+	return img
+}
+
+func TestPurgeDeletedImages(t *testing.T) {
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name      string
+		retention map[string]time.Duration
+		wantGone  []string
+		wantKept  []string
+	}{
+		{
+			name: "purges images past the retention of their owner type",
+			retention: map[string]time.Duration{
+				image.OwnerTypeProductDraft: 7 * day,
+				image.OwnerTypeProduct:      30 * day,
+			},
+			wantGone: []string{"old-draft"},
+			wantKept: []string{"new-draft", "product", "live"},
+		},
+		{
+			name: "zero retention purges every deleted image",
+			retention: map[string]time.Duration{
+				image.OwnerTypeProductDraft: 0,
+				image.OwnerTypeProduct:      0,
+			},
+			wantGone: []string{"old-draft", "new-draft", "product"},
+			wantKept: []string{"live"},
+		},
+		{
+			name:      "owner types without retention are skipped",
+			retention: map[string]time.Duration{image.OwnerTypeUser: 0},
+			wantKept:  []string{"old-draft", "new-draft", "product", "live"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeImageRepo(
+				deletedImage(t, "old-draft", image.OwnerTypeProductDraft, 10*day),
+				deletedImage(t, "new-draft", image.OwnerTypeProductDraft, time.Hour),
+				deletedImage(t, "product", image.OwnerTypeProduct, 10*day),
+				newTestImage(t, "live", image.OwnerTypeProductDraft, "owner-1"),
+			)
+			storage := newFakeStorage()
+			h := NewPurgeDeletedImagesHandler(repo, NewDeleteImageHandler(repo, storage))
+
+			purged, err := h.Handle(context.Background(), PurgeDeletedImagesCommand{Retention: tt.retention})
+			if err != nil {
+				t.Fatalf("purge: %v", err)
+			}
+			if purged != len(tt.wantGone) {
+				t.Errorf("purged %d images, want %d", purged, len(tt.wantGone))
+			}
+			for _, id := range tt.wantGone {
+				if repo.get(id) != nil {
+					t.Errorf("image %s was not purged", id)
+				}
+			}
+			for _, id := range tt.wantKept {
+				if repo.get(id) == nil {
+					t.Errorf("image %s was purged", id)
+				}
+			}
+			if len(storage.deleted) != len(tt.wantGone) {
+				t.Errorf("deleted %d objects, want %d", len(storage.deleted), len(tt.wantGone))
+			}
+		})
+	}
+}
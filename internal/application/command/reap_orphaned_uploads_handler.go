@@ -0,0 +1,140 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"go.uber.org/zap"
+)
+
+// reapPageSize is how many objects are listed and looked up in one go
+const reapPageSize = 1000
+
+// ReapOrphanedUploadsCommand represents a request to delete uploaded objects that were
+// never confirmed. Objects younger than OlderThan are left alone, since their upload may
+// still be confirmed or promoted.
+type ReapOrphanedUploadsCommand struct {
+	OlderThan time.Duration
+	DryRun    bool // only report orphans
+}
+
+// ReapOrphanedUploadsResult counts what a reaper run found, per owner prefix
+type ReapOrphanedUploadsResult struct {
+	Prefixes []PrefixReapResult
+}
+
+// PrefixReapResult counts the objects of one owner prefix
+type PrefixReapResult struct {
+	Prefix       string
+	Scanned      int
+	Orphaned     int
+	Deleted      int
+	DeletedBytes int64
+}
+
+// ReapOrphanedUploadsCommandHandler handles ReapOrphanedUploadsCommand
+type ReapOrphanedUploadsCommandHandler interface {
+	Handle(ctx context.Context, cmd ReapOrphanedUploadsCommand) (*ReapOrphanedUploadsResult, error)
+}
+
+type reapOrphanedUploadsHandler struct {
+	repo       image.Repository
+	objStorage abstraction.ObjectStorage
+}
+
+func NewReapOrphanedUploadsHandler(repo image.Repository, storage abstraction.ObjectStorage) ReapOrphanedUploadsCommandHandler {
+	return &reapOrphanedUploadsHandler{
+		repo:       repo,
+		objStorage: storage,
+	}
+}
+
+func (h *reapOrphanedUploadsHandler) Handle(ctx context.Context, cmd ReapOrphanedUploadsCommand) (*ReapOrphanedUploadsResult, error) {
+	cutoff := time.Now().Add(-cmd.OlderThan)
+	result := &ReapOrphanedUploadsResult{}
+
+	for _, prefix := range ownerPrefixes() {
+		pr, err := h.reapPrefix(ctx, prefix, cutoff, cmd.DryRun)
+		result.Prefixes = append(result.Prefixes, pr)
+		if err != nil {
+			return result, err
+		}
+
+		if pr.Orphaned > 0 {
+			h.log(ctx).Info("orphaned uploads found",
+				zap.String("prefix", prefix),
+				zap.Int("orphaned", pr.Orphaned),
+				zap.Int("deleted", pr.Deleted),
+				zap.Bool("dryRun", cmd.DryRun))
+		}
+	}
+
+	return result, nil
+}
+
+func (h *reapOrphanedUploadsHandler) reapPrefix(ctx context.Context, prefix string, cutoff time.Time, dryRun bool) (PrefixReapResult, error) {
+	pr := PrefixReapResult{Prefix: prefix}
+	token := ""
+
+	for {
+		page, err := h.objStorage.ListObjects(ctx, &abstraction.ListObjectsInput{
+			Prefix:            prefix,
+			ContinuationToken: token,
+			MaxKeys:           reapPageSize,
+		})
+		if err != nil {
+			return pr, fmt.Errorf("list objects under %s: %w", prefix, err)
+		}
+		pr.Scanned += len(page.Objects)
+
+		// Only objects past the grace period are candidates
+		var candidates []abstraction.StoredObject
+		keys := make([]string, 0, len(page.Objects))
+		for _, obj := range page.Objects {
+			if obj.LastModified.Before(cutoff) {
+				candidates = append(candidates, obj)
+				keys = append(keys, obj.Key)
+			}
+		}
+
+		existing, err := h.repo.FindExistingKeys(ctx, keys)
+		if err != nil {
+			return pr, fmt.Errorf("find image keys: %w", err)
+		}
+
+		for _, obj := range candidates {
+			if existing[obj.Key] {
+				continue
+			}
+			pr.Orphaned++
+
+			if dryRun {
+				h.log(ctx).Info("orphaned upload (dry run)", zap.String("key", obj.Key), zap.Time("lastModified", obj.LastModified))
+				continue
+			}
+
+			err := h.objStorage.DeleteObject(ctx, &abstraction.DeleteObjectInput{
+				Key: obj.Key,
+			})
+			if err != nil {
+				h.log(ctx).Warn("failed to delete orphaned upload", zap.Error(err), zap.String("key", obj.Key))
+				continue
+			}
+			pr.Deleted++
+			pr.DeletedBytes += obj.Size
+		}
+
+		if page.NextContinuationToken == "" {
+			return pr, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+func (h *reapOrphanedUploadsHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "reap-orphaned-uploads-handler"))
+}
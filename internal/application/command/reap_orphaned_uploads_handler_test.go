@@ -0,0 +1,117 @@
+package command
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// listingStorage lists the objects of fakeStorage two at a time, so that reaping pages
+type listingStorage struct {
+	*fakeStorage
+	modified map[string]time.Time
+}
+
+func (s *listingStorage) ListObjects(_ context.Context, input *abstraction.ListObjectsInput) (*abstraction.ListObjectsOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, input.Prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	start := 0
+	if input.ContinuationToken != "" {
+		start, _ = strconv.Atoi(input.ContinuationToken)
+	}
+	end := min(start+2, len(keys))
+
+	out := &abstraction.ListObjectsOutput{}
+	for _, key := range keys[start:end] {
+		out.Objects = append(out.Objects, abstraction.StoredObject{
+			Key:          key,
+			Size:         int64(len(s.objects[key])),
+			LastModified: s.modified[key],
+		})
+	}
+	if end < len(keys) {
+		out.NextContinuationToken = strconv.Itoa(end)
+	}
+	return out, nil
+}
+
+func TestReapOrphanedUploads(t *testing.T) {
+	old := time.Now().Add(-72 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+
+	confirmed := newTestImage(t, "img-1", image.OwnerTypeProduct, "p-1")
+	trashed := newTestImage(t, "img-2", image.OwnerTypeProductDraft, "d-1")
+	trashed.MarkAsDeleted()
+
+	storage := &listingStorage{fakeStorage: newFakeStorage(), modified: map[string]time.Time{}}
+	for key, modified := range map[string]time.Time{
+		confirmed.Key:                   old,
+		trashed.Key:                     old,
+		"product-drafts/d-1/orphan.jpg": old,
+		"product-drafts/d-1/fresh.jpg":  recent,
+		"products/p-1/orphan.png":       old,
+		"users/u-1/orphan.webp":         old,
+	} {
+		storage.put(key, []byte("12345"))
+		storage.modified[key] = modified
+	}
+	repo := newFakeImageRepo(confirmed, trashed)
+	handler := NewReapOrphanedUploadsHandler(repo, storage)
+
+	// A dry run only reports
+	result, err := handler.Handle(context.Background(), ReapOrphanedUploadsCommand{OlderThan: 48 * time.Hour, DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if orphaned := totalOrphaned(result); orphaned != 3 {
+		t.Fatalf("dry run found %d orphans, want 3", orphaned)
+	}
+	if len(storage.deleted) != 0 {
+		t.Fatalf("dry run deleted %v", storage.deleted)
+	}
+
+	result, err = handler.Handle(context.Background(), ReapOrphanedUploadsCommand{OlderThan: 48 * time.Hour})
+	if err != nil {
+		t.Fatalf("reap: %v", err)
+	}
+
+	want := []string{"product-drafts/d-1/orphan.jpg", "products/p-1/orphan.png", "users/u-1/orphan.webp"}
+	deleted := slices.Sorted(slices.Values(storage.deleted))
+	if !slices.Equal(deleted, want) {
+		t.Fatalf("deleted %v, want %v", deleted, want)
+	}
+
+	drafts := result.Prefixes[0]
+	if drafts.Prefix != "product-drafts/" || drafts.Scanned != 3 || drafts.Orphaned != 1 || drafts.Deleted != 1 || drafts.DeletedBytes != 5 {
+		t.Fatalf("draft prefix result = %+v", drafts)
+	}
+	for _, key := range []string{confirmed.Key, trashed.Key, "product-drafts/d-1/fresh.jpg"} {
+		if !storage.has(key) {
+			t.Errorf("%s was deleted", key)
+		}
+	}
+}
+
+func totalOrphaned(result *ReapOrphanedUploadsResult) int {
+	n := 0
+	for _, pr := range result.Prefixes {
+		n += pr.Orphaned
+	}
+	return n
+}
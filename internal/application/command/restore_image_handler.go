@@ -0,0 +1,57 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"go.uber.org/zap"
+)
+
+// RestoreImageCommand represents a request to restore a soft deleted image
+type RestoreImageCommand struct {
+	ImageID string
+}
+
+// RestoreImageCommandHandler handles RestoreImageCommand
+type RestoreImageCommandHandler interface {
+	Handle(ctx context.Context, cmd RestoreImageCommand) (*image.Image, error)
+}
+
+type restoreImageHandler struct {
+	repo image.Repository
+}
+
+func NewRestoreImageHandler(repo image.Repository) RestoreImageCommandHandler {
+	return &restoreImageHandler{repo: repo}
+}
+
+func (h *restoreImageHandler) Handle(ctx context.Context, cmd RestoreImageCommand) (*image.Image, error) {
+	img, err := h.repo.FindByID(ctx, cmd.ImageID)
+	if err != nil {
+		if errors.Is(err, persistence.ErrEntityNotFound) {
+			return nil, image.ErrImageNotFound
+		}
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	if err := img.Restore(); err != nil {
+		return nil, err
+	}
+
+	updated, err := h.repo.Update(ctx, img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore image: %w", err)
+	}
+
+	h.log(ctx).Debug("image restored", zap.String("id", updated.ID), zap.String("status", string(updated.Status)))
+
+	return updated, nil
+}
+
+func (h *restoreImageHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "restore-image-handler"))
+}
@@ -0,0 +1,70 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/promotion"
+	"go.uber.org/zap"
+)
+
+const resumeBatchSize = 100
+
+// ResumePromotionsCommand represents a request to run the interrupted promotion jobs.
+// Jobs modified within StaleAfter are assumed to be running elsewhere and are left alone.
+type ResumePromotionsCommand struct {
+	StaleAfter time.Duration
+}
+
+// ResumePromotionsCommandHandler handles ResumePromotionsCommand
+type ResumePromotionsCommandHandler interface {
+	Handle(ctx context.Context, cmd ResumePromotionsCommand) (int, error)
+}
+
+type resumePromotionsHandler struct {
+	jobs   promotion.Repository
+	runner PromotionRunner
+}
+
+func NewResumePromotionsHandler(jobs promotion.Repository, runner PromotionRunner) ResumePromotionsCommandHandler {
+	return &resumePromotionsHandler{
+		jobs:   jobs,
+		runner: runner,
+	}
+}
+
+// Handle runs the interrupted jobs and returns how many were resumed.
+// Jobs that fail again stay failed for an operator to retry.
+func (h *resumePromotionsHandler) Handle(ctx context.Context, cmd ResumePromotionsCommand) (int, error) {
+	jobs, err := h.jobs.FindPendingBefore(ctx, time.Now().UTC().Add(-cmd.StaleAfter), resumeBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("find interrupted promotion jobs: %w", err)
+	}
+
+	resumed := 0
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return resumed, err
+		}
+
+		_, err := h.runner.Run(ctx, job)
+		if err != nil && !errors.Is(err, promotion.ErrJobFailed) {
+			h.log(ctx).Warn("failed to resume promotion job", zap.Error(err), zap.String("jobId", job.ID))
+			continue
+		}
+		resumed++
+	}
+
+	if resumed > 0 {
+		h.log(ctx).Info("promotion jobs resumed", zap.Int("count", resumed))
+	}
+
+	return resumed, nil
+}
+
+func (h *resumePromotionsHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "resume-promotions-handler"))
+}
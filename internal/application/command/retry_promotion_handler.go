@@ -0,0 +1,66 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/promotion"
+	"go.uber.org/zap"
+)
+
+// RetryPromotionCommand represents a request to run a failed promotion job again
+type RetryPromotionCommand struct {
+	JobID string
+}
+
+// RetryPromotionCommandHandler handles RetryPromotionCommand
+type RetryPromotionCommandHandler interface {
+	Handle(ctx context.Context, cmd RetryPromotionCommand) (*promotion.Job, error)
+}
+
+type retryPromotionHandler struct {
+	jobs   promotion.Repository
+	runner PromotionRunner
+}
+
+func NewRetryPromotionHandler(jobs promotion.Repository, runner PromotionRunner) RetryPromotionCommandHandler {
+	return &retryPromotionHandler{
+		jobs:   jobs,
+		runner: runner,
+	}
+}
+
+// Handle runs the job from its last completed steps and returns it in its new state,
+// which is failed again if some image still cannot be promoted
+func (h *retryPromotionHandler) Handle(ctx context.Context, cmd RetryPromotionCommand) (*promotion.Job, error) {
+	job, err := h.jobs.FindByID(ctx, cmd.JobID)
+	if err != nil {
+		if errors.Is(err, persistence.ErrEntityNotFound) {
+			return nil, promotion.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get promotion job: %w", err)
+	}
+
+	if err := job.Retry(); err != nil {
+		return nil, err
+	}
+	updated, err := h.jobs.Update(ctx, job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reset promotion job: %w", err)
+	}
+
+	if _, err := h.runner.Run(ctx, updated); err != nil && !errors.Is(err, promotion.ErrJobFailed) {
+		return nil, fmt.Errorf("failed to run promotion job: %w", err)
+	}
+
+	h.log(ctx).Info("promotion job retried", zap.String("jobId", updated.ID), zap.String("status", string(updated.Status)))
+
+	return updated, nil
+}
+
+func (h *retryPromotionHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "retry-promotion-handler"))
+}
@@ -0,0 +1,80 @@
+package command
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/promotion"
+	"go.uber.org/zap"
+)
+
+// StartPromotionCommandHandler plans the promotion of draft images and runs it in the background.
+// The returned jobs can be polled for their status.
+type StartPromotionCommandHandler interface {
+	Handle(ctx context.Context, cmd PromoteImagesCommand) ([]*promotion.Job, error)
+}
+
+type startPromotionHandler struct {
+	runner     PromotionRunner
+	jobs       promotion.Repository
+	background *BackgroundRunner
+}
+
+func NewStartPromotionHandler(runner PromotionRunner, jobs promotion.Repository, background *BackgroundRunner) StartPromotionCommandHandler {
+	return &startPromotionHandler{
+		runner:     runner,
+		jobs:       jobs,
+		background: background,
+	}
+}
+
+func (h *startPromotionHandler) Handle(ctx context.Context, cmd PromoteImagesCommand) ([]*promotion.Job, error) {
+	jobs, err := h.runner.Plan(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		// A completed job is a replay of an earlier attempt
+		if job.Status == promotion.StatusPending {
+			ids = append(ids, job.ID)
+		}
+	}
+
+	// The run outlives the request, so it works on its own copies of the jobs.
+	// Jobs interrupted by a shutdown stay pending and are resumed on the next startup.
+	log := h.log(ctx)
+	started := h.background.Go(func(ctx context.Context) {
+		for _, id := range ids {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := h.run(ctx, id); err != nil {
+				log.Error("failed to run promotion job", zap.Error(err), zap.String("jobId", id))
+			}
+		}
+	})
+	if !started {
+		log.Warn("shutting down, promotion jobs left for resumption", zap.Strings("jobIds", ids))
+	}
+
+	return jobs, nil
+}
+
+func (h *startPromotionHandler) run(ctx context.Context, id string) error {
+	job, err := h.jobs.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	// Failed items are recorded on the job
+	if _, err := h.runner.Run(ctx, job); err != nil && !errors.Is(err, promotion.ErrJobFailed) {
+		return err
+	}
+	return nil
+}
+
+func (h *startPromotionHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "start-promotion-handler"))
+}
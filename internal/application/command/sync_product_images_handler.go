@@ -0,0 +1,209 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"go.uber.org/zap"
+)
+
+// ProductImageRef is an image referenced by a product, with its place in the gallery
+type ProductImageRef struct {
+	ImageID  string
+	Role     string // empty keeps the current role
+	Position int
+}
+
+// SyncProductImagesCommand represents images a product references. Trashed images are
+// restored and draft images of the same ID are promoted. Only a complete set is authoritative:
+// then unreferenced product images are soft deleted and roles and positions are applied.
+type SyncProductImagesCommand struct {
+	ProductID string
+	Images    []ProductImageRef
+	Complete  bool // Images is the whole gallery of the product
+}
+
+// SyncProductImagesCommandHandler handles SyncProductImagesCommand
+type SyncProductImagesCommandHandler interface {
+	Handle(ctx context.Context, cmd SyncProductImagesCommand) error
+}
+
+type syncProductImagesHandler struct {
+	repo          image.Repository
+	promoteImages PromoteImagesCommandHandler
+	deleteImage   DeleteImageCommandHandler
+}
+
+func NewSyncProductImagesHandler(repo image.Repository, promoteImages PromoteImagesCommandHandler, deleteImage DeleteImageCommandHandler) SyncProductImagesCommandHandler {
+	return &syncProductImagesHandler{
+		repo:          repo,
+		promoteImages: promoteImages,
+		deleteImage:   deleteImage,
+	}
+}
+
+// Handle reconciles the stored images with the referenced set. It only acts on differences,
+// so handling the same set again changes nothing.
+func (h *syncProductImagesHandler) Handle(ctx context.Context, cmd SyncProductImagesCommand) error {
+	wanted := make(map[string]ProductImageRef, len(cmd.Images))
+	for _, ref := range cmd.Images {
+		wanted[ref.ImageID] = ref
+	}
+
+	current, err := h.repo.FindByOwner(ctx, image.OwnerTypeProduct, cmd.ProductID, nil)
+	if err != nil {
+		return fmt.Errorf("list product images: %w", err)
+	}
+
+	// Drop images the product no longer references
+	owned := make(map[string]*image.Image, len(current))
+	for _, img := range current {
+		if _, ok := wanted[img.ID]; ok {
+			owned[img.ID] = img
+			continue
+		}
+		if !cmd.Complete {
+			continue
+		}
+		if err := h.deleteImage.Handle(ctx, DeleteImageCommand{ImageID: img.ID}); err != nil {
+			return fmt.Errorf("delete unreferenced image %s: %w", img.ID, err)
+		}
+		h.log(ctx).Debug("unreferenced image deleted", zap.String("id", img.ID), zap.String("productId", cmd.ProductID))
+	}
+
+	// Bring back referenced images that were trashed, e.g. by an earlier gallery change
+	restored, err := h.restoreTrashed(ctx, cmd.ProductID, wanted, owned)
+	if err != nil {
+		return err
+	}
+	for _, img := range restored {
+		if img.OwnerType == image.OwnerTypeProduct {
+			owned[img.ID] = img
+		}
+	}
+
+	// Promote referenced images that are still in the draft
+	promoted, err := h.promoteMissing(ctx, cmd.ProductID, wanted, owned)
+	if err != nil {
+		return err
+	}
+	for _, img := range promoted {
+		owned[img.ID] = img
+	}
+
+	// Apply roles and ordering
+	if !cmd.Complete {
+		return nil
+	}
+	for id, ref := range wanted {
+		img, ok := owned[id]
+		if !ok {
+			h.log(ctx).Warn("referenced image not found", zap.String("id", id), zap.String("productId", cmd.ProductID))
+			continue
+		}
+		if err := h.applyRef(ctx, img, ref); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreTrashed restores referenced images of the product or its draft that are in the trash
+func (h *syncProductImagesHandler) restoreTrashed(ctx context.Context, productID string, wanted map[string]ProductImageRef, owned map[string]*image.Image) ([]*image.Image, error) {
+	var restored []*image.Image
+	for id := range wanted {
+		if _, ok := owned[id]; ok {
+			continue
+		}
+
+		img, err := h.repo.FindByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, persistence.ErrEntityNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("get image %s: %w", id, err)
+		}
+		// The draft shares its ID with the product it becomes
+		ownedByProduct := img.OwnerID == productID &&
+			(img.OwnerType == image.OwnerTypeProduct || img.OwnerType == image.OwnerTypeProductDraft)
+		if !img.IsDeleted() || !ownedByProduct {
+			continue
+		}
+
+		if err := img.Restore(); err != nil {
+			return nil, err
+		}
+		updated, err := h.repo.Update(ctx, img)
+		if err != nil {
+			return nil, fmt.Errorf("restore image %s: %w", id, err)
+		}
+		h.log(ctx).Debug("referenced image restored", zap.String("id", id), zap.String("productId", productID))
+		restored = append(restored, updated)
+	}
+	return restored, nil
+}
+
+func (h *syncProductImagesHandler) promoteMissing(ctx context.Context, productID string, wanted map[string]ProductImageRef, owned map[string]*image.Image) ([]*image.Image, error) {
+	var missing []string
+	for id := range wanted {
+		if _, ok := owned[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	// The draft shares its ID with the product it becomes
+	drafts, err := h.repo.FindByOwner(ctx, image.OwnerTypeProductDraft, productID, missing)
+	if err != nil {
+		return nil, fmt.Errorf("list draft images: %w", err)
+	}
+	if len(drafts) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(drafts))
+	for _, img := range drafts {
+		ids = append(ids, img.ID)
+	}
+
+	promoted, err := h.promoteImages.Handle(ctx, PromoteImagesCommand{
+		DraftID:   productID,
+		ImageIDs:  &ids,
+		ProductID: productID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("promote draft images: %w", err)
+	}
+	return promoted, nil
+}
+
+func (h *syncProductImagesHandler) applyRef(ctx context.Context, img *image.Image, ref ProductImageRef) error {
+	changed := false
+	if ref.Role != "" && img.Role != ref.Role {
+		img.UpdateRole(ref.Role)
+		changed = true
+	}
+	if img.Position != ref.Position {
+		img.UpdatePosition(ref.Position)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	if _, err := h.repo.Update(ctx, img); err != nil {
+		return fmt.Errorf("update image %s: %w", img.ID, err)
+	}
+	return nil
+}
+
+func (h *syncProductImagesHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "sync-product-images-handler"))
+}
@@ -0,0 +1,109 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// recordingPromoter records promotion requests without promoting anything
+type recordingPromoter struct {
+	PromoteImagesCommandHandler
+	imageIDs []string
+}
+
+func (p *recordingPromoter) Handle(_ context.Context, cmd PromoteImagesCommand) ([]*image.Image, error) {
+	if cmd.ImageIDs != nil {
+		p.imageIDs = append(p.imageIDs, *cmd.ImageIDs...)
+	}
+	return nil, nil
+}
+
+func TestSyncProductImages(t *testing.T) {
+	trashed := func(t *testing.T, id, ownerType, ownerID string) *image.Image {
+		img := newTestImage(t, id, ownerType, ownerID)
+		img.MarkAsDeleted()
+		return img
+	}
+
+	tests := []struct {
+		name         string
+		cmd          SyncProductImagesCommand
+		wantLive     []string
+		wantDeleted  []string
+		wantPromoted []string
+	}{
+		{
+			name:        "complete gallery deletes unreferenced images",
+			cmd:         SyncProductImagesCommand{Images: []ProductImageRef{{ImageID: "a"}}, Complete: true},
+			wantLive:    []string{"a"},
+			wantDeleted: []string{"b", "trashed", "other-trashed"},
+		},
+		{
+			name:        "complete empty gallery deletes every image",
+			cmd:         SyncProductImagesCommand{Complete: true},
+			wantDeleted: []string{"a", "b"},
+		},
+		{
+			name:     "main image only keeps the gallery",
+			cmd:      SyncProductImagesCommand{Images: []ProductImageRef{{ImageID: "a"}}},
+			wantLive: []string{"a", "b"},
+		},
+		{
+			name:        "referenced trashed image is restored",
+			cmd:         SyncProductImagesCommand{Images: []ProductImageRef{{ImageID: "trashed"}}},
+			wantLive:    []string{"a", "b", "trashed"},
+			wantDeleted: []string{"other-trashed"},
+		},
+		{
+			name:        "trashed image of another product is left alone",
+			cmd:         SyncProductImagesCommand{Images: []ProductImageRef{{ImageID: "other-trashed"}}},
+			wantDeleted: []string{"other-trashed"},
+		},
+		{
+			name:         "referenced trashed draft image is restored and promoted",
+			cmd:          SyncProductImagesCommand{Images: []ProductImageRef{{ImageID: "trashed-draft"}}},
+			wantLive:     []string{"trashed-draft"},
+			wantPromoted: []string{"trashed-draft"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeImageRepo(
+				newTestImage(t, "a", image.OwnerTypeProduct, "p-1"),
+				newTestImage(t, "b", image.OwnerTypeProduct, "p-1"),
+				trashed(t, "trashed", image.OwnerTypeProduct, "p-1"),
+				trashed(t, "other-trashed", image.OwnerTypeProduct, "p-2"),
+				trashed(t, "trashed-draft", image.OwnerTypeProductDraft, "p-1"),
+			)
+			promoter := &recordingPromoter{}
+			handler := NewSyncProductImagesHandler(repo, promoter, NewDeleteImageHandler(repo, newFakeStorage()))
+
+			tt.cmd.ProductID = "p-1"
+			if err := handler.Handle(context.Background(), tt.cmd); err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+
+			for _, id := range tt.wantLive {
+				if img := repo.get(id); img == nil || img.IsDeleted() {
+					t.Errorf("image %s is not live", id)
+				}
+			}
+			for _, id := range tt.wantDeleted {
+				if img := repo.get(id); img == nil || !img.IsDeleted() {
+					t.Errorf("image %s is not in the trash", id)
+				}
+			}
+			if len(promoter.imageIDs) != len(tt.wantPromoted) {
+				t.Fatalf("promoted %v, want %v", promoter.imageIDs, tt.wantPromoted)
+			}
+			for i, id := range tt.wantPromoted {
+				if promoter.imageIDs[i] != id {
+					t.Errorf("promoted %v, want %v", promoter.imageIDs, tt.wantPromoted)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"go.uber.org/zap"
+)
+
+// UpdateImageCommand represents a request to update mutable image metadata
+type UpdateImageCommand struct {
+	ImageID string
+	Alt     *string
+	Role    *string
+	// FocalPoint and Crops are non-destructive edits applied on delivery; Crops replaces the whole set
+	FocalPoint *image.FocalPoint
+	Crops      *[]image.Crop
+	// Version is the version the client based its changes on; a mismatch is a conflict
+	Version *int
+	// IfMatch holds the versions listed in an If-Match header; nil when the header is absent.
+	// An empty non-nil slice never matches.
+	IfMatch []int
+}
+
+// UpdateImageCommandHandler handles UpdateImageCommand
+type UpdateImageCommandHandler interface {
+	Handle(ctx context.Context, cmd UpdateImageCommand) (*image.Image, error)
+}
+
+type updateImageHandler struct {
+	repo  image.Repository
+	roles []string
+}
+
+// NewUpdateImageHandler creates the handler. Roles lists the roles an image may be given.
+func NewUpdateImageHandler(repo image.Repository, roles []string) UpdateImageCommandHandler {
+	return &updateImageHandler{
+		repo:  repo,
+		roles: roles,
+	}
+}
+
+func (h *updateImageHandler) Handle(ctx context.Context, cmd UpdateImageCommand) (*image.Image, error) {
+	if cmd.Role != nil && !slices.Contains(h.roles, *cmd.Role) {
+		return nil, fmt.Errorf("%w: %q", image.ErrInvalidRole, *cmd.Role)
+	}
+
+	img, err := h.repo.FindByID(ctx, cmd.ImageID)
+	if err != nil {
+		if errors.Is(err, persistence.ErrEntityNotFound) {
+			return nil, image.ErrImageNotFound
+		}
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	if img.IsDeleted() {
+		return nil, image.ErrImageAlreadyDeleted
+	}
+
+	if cmd.IfMatch != nil && !slices.Contains(cmd.IfMatch, img.Version) {
+		return nil, image.ErrPreconditionFailed
+	}
+
+	if cmd.Version != nil && *cmd.Version != img.Version {
+		return nil, image.ErrVersionConflict
+	}
+
+	// Only values that differ are applied, so that a repeated request keeps the version and ETag
+	changed := false
+	if cmd.Alt != nil && *cmd.Alt != img.Alt {
+		img.UpdateAlt(*cmd.Alt)
+		changed = true
+	}
+	if cmd.Role != nil && *cmd.Role != img.Role {
+		img.UpdateRole(*cmd.Role)
+		changed = true
+	}
+	if cmd.FocalPoint != nil && (img.FocalPoint == nil || *cmd.FocalPoint != *img.FocalPoint) {
+		if err := img.UpdateFocalPoint(*cmd.FocalPoint); err != nil {
+			return nil, err
+		}
+		changed = true
+	}
+	if cmd.Crops != nil && !slices.Equal(*cmd.Crops, img.Crops) {
+		if err := img.UpdateCrops(*cmd.Crops); err != nil {
+			return nil, err
+		}
+		changed = true
+	}
+
+	if !changed {
+		return img, nil
+	}
+
+	// The repository rejects the write if the stored version changed since FindByID
+	updated, err := h.repo.Update(ctx, img)
+	if err != nil {
+		if errors.Is(err, persistence.ErrOptimisticLocking) {
+			return nil, image.ErrVersionConflict
+		}
+		return nil, fmt.Errorf("failed to update image: %w", err)
+	}
+
+	h.log(ctx).Debug("image updated", zap.String("id", updated.ID), zap.Int("version", updated.Version))
+
+	return updated, nil
+}
+
+func (h *updateImageHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "update-image-handler"))
+}
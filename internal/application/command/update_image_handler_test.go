@@ -0,0 +1,109 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+func TestUpdateImage(t *testing.T) {
+	alt, main, unknown := "front view", "main", "hero"
+	stale := 7
+	fp := image.FocalPoint{X: 0.5, Y: 0.5}
+	noCrops := []image.Crop{}
+
+	tests := []struct {
+		name        string
+		cmd         UpdateImageCommand
+		wantErr     error
+		wantVersion int
+	}{
+		{
+			name:        "changed alt bumps the version",
+			cmd:         UpdateImageCommand{Alt: &alt},
+			wantVersion: 2,
+		},
+		{
+			name:        "allowed role",
+			cmd:         UpdateImageCommand{Role: &main},
+			wantVersion: 2,
+		},
+		{
+			name:    "unknown role is rejected",
+			cmd:     UpdateImageCommand{Role: &unknown},
+			wantErr: image.ErrInvalidRole,
+		},
+		{
+			name:        "focal point",
+			cmd:         UpdateImageCommand{FocalPoint: &fp},
+			wantVersion: 2,
+		},
+		{
+			name:        "unchanged values keep the version",
+			cmd:         UpdateImageCommand{Alt: new(string), Crops: &noCrops},
+			wantVersion: 1,
+		},
+		{
+			name:        "matching If-Match",
+			cmd:         UpdateImageCommand{Alt: &alt, IfMatch: []int{3, 1}},
+			wantVersion: 2,
+		},
+		{
+			name:    "If-Match of another version",
+			cmd:     UpdateImageCommand{Alt: &alt, IfMatch: []int{3}},
+			wantErr: image.ErrPreconditionFailed,
+		},
+		{
+			name:    "If-Match without versions of this service",
+			cmd:     UpdateImageCommand{Alt: &alt, IfMatch: []int{}},
+			wantErr: image.ErrPreconditionFailed,
+		},
+		{
+			name:    "stale version",
+			cmd:     UpdateImageCommand{Alt: &alt, Version: &stale},
+			wantErr: image.ErrVersionConflict,
+		},
+		{
+			name:    "invalid focal point",
+			cmd:     UpdateImageCommand{FocalPoint: &image.FocalPoint{X: 2}},
+			wantErr: image.ErrInvalidEdit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeImageRepo(newTestImage(t, "img-1", image.OwnerTypeProduct, "product-1"))
+			handler := NewUpdateImageHandler(repo, []string{"main", "gallery"})
+
+			tt.cmd.ImageID = "img-1"
+			img, err := handler.Handle(context.Background(), tt.cmd)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if repo.get("img-1").Version != 1 {
+					t.Fatal("rejected update was stored")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+
+			if img.Version != tt.wantVersion || repo.get("img-1").Version != tt.wantVersion {
+				t.Fatalf("version = %d, stored %d, want %d", img.Version, repo.get("img-1").Version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestUpdateImageUnknown(t *testing.T) {
+	handler := NewUpdateImageHandler(newFakeImageRepo(), []string{"gallery"})
+
+	_, err := handler.Handle(context.Background(), UpdateImageCommand{ImageID: "missing"})
+	if !errors.Is(err, image.ErrImageNotFound) {
+		t.Fatalf("err = %v, want %v", err, image.ErrImageNotFound)
+	}
+}
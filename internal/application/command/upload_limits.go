@@ -0,0 +1,23 @@
+package command
+
+import "github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+
+// UploadLimits bounds the size of uploaded files
+type UploadLimits struct {
+	MaxBytes          int64 // single-request uploads
+	MaxMultipartBytes int64 // files assembled from parts
+	MaxAvatarBytes    int64 // user avatars, regardless of upload method
+	MaxPixels         int64 // decoded width times height, regardless of file size
+}
+
+// maxBytesFor returns the size limit for an upload by the given owner type; zero means unlimited
+func (l UploadLimits) maxBytesFor(ownerType string, multipart bool) int64 {
+	limit := l.MaxBytes
+	if multipart {
+		limit = l.MaxMultipartBytes
+	}
+	if ownerType == image.OwnerTypeUser && l.MaxAvatarBytes > 0 && (limit <= 0 || l.MaxAvatarBytes < limit) {
+		limit = l.MaxAvatarBytes
+	}
+	return limit
+}
@@ -4,6 +4,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/command"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/processing"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/query"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
 	"github.com/spf13/viper"
 )
 
@@ -14,6 +19,96 @@ type Config struct {
 
 	// MaxUploadBytes is the maximum allowed file upload size in bytes
 	MaxUploadBytes int64 `mapstructure:"max-upload-bytes"`
+
+	// MaxMultipartUploadBytes is the maximum allowed size of a file uploaded in parts
+	MaxMultipartUploadBytes int64 `mapstructure:"max-multipart-upload-bytes"`
+
+	// MaxAvatarBytes is the maximum allowed size of a user avatar
+	MaxAvatarBytes int64 `mapstructure:"max-avatar-bytes"`
+
+	// MaxPixels is the maximum allowed width times height of an image
+	MaxPixels int64 `mapstructure:"max-pixels"`
+
+	// DuplicatePolicy is what happens to near-duplicate uploads of the same owner: off, flag or reject.
+	// With reject, confirming a near-duplicate fails with a conflict; an image whose duplicate is only
+	// found by the processing worker fails processing with the duplicate as its failure reason.
+	DuplicatePolicy string `mapstructure:"duplicate-policy"`
+
+	// DuplicateMaxDistance is the maximum number of differing perceptual hash bits between near-duplicates,
+	// 4 when not configured; 0 matches identical hashes only
+	DuplicateMaxDistance *int `mapstructure:"duplicate-max-distance"`
+
+	// MultipartPartSize is the part size suggested to clients for multipart uploads
+	MultipartPartSize int64 `mapstructure:"multipart-part-size"`
+
+	// MultipartStaleAfter is the age after which unfinished multipart uploads are aborted
+	MultipartStaleAfter time.Duration `mapstructure:"multipart-stale-after"`
+
+	// MultipartCleanupInterval is how often stale multipart uploads are looked for
+	MultipartCleanupInterval time.Duration `mapstructure:"multipart-cleanup-interval"`
+
+	// OrphanGracePeriod is the age after which an uploaded object without an image is deleted
+	OrphanGracePeriod time.Duration `mapstructure:"orphan-grace-period"`
+
+	// OrphanReapInterval is how often orphaned uploads are looked for
+	OrphanReapInterval time.Duration `mapstructure:"orphan-reap-interval"`
+
+	// OrphanReapDryRun only reports orphaned uploads instead of deleting them
+	OrphanReapDryRun bool `mapstructure:"orphan-reap-dry-run"`
+
+	// TrashRetentionDays is how long soft deleted images are kept before they are purged
+	TrashRetentionDays TrashRetention `mapstructure:"trash-retention-days"`
+
+	// TrashPurgeInterval is how often expired soft deleted images are purged
+	TrashPurgeInterval time.Duration `mapstructure:"trash-purge-interval"`
+
+	// PromotionResumeAfter is how long a pending promotion job stays untouched before it is
+	// considered interrupted and resumed; interrupted jobs are looked for on startup and at this interval
+	PromotionResumeAfter time.Duration `mapstructure:"promotion-resume-after"`
+
+	// ProcessingInterval is how often images waiting in processing are processed
+	ProcessingInterval time.Duration `mapstructure:"processing-interval"`
+
+	// ProcessingMaxAttempts is how many times in a row processing of an image may fail
+	// transiently before the image is marked as failed
+	ProcessingMaxAttempts int `mapstructure:"processing-max-attempts"`
+
+	// ImageRoles are the roles an image may be given by an update
+	ImageRoles []string `mapstructure:"image-roles"`
+
+	// DeliveryPresets are named delivery transformations, so that clients share cached variants
+	DeliveryPresets map[string]DeliveryPreset `mapstructure:"delivery-presets"`
+
+	// SrcsetWidths are the default breakpoints of responsive image sets
+	SrcsetWidths []int `mapstructure:"srcset-widths"`
+
+	// SrcsetFormats are the default formats of responsive image sets, in order of preference
+	SrcsetFormats []string `mapstructure:"srcset-formats"`
+
+	// RenderCacheMaxAge is how long clients and CDNs may cache a render redirect
+	RenderCacheMaxAge time.Duration `mapstructure:"render-cache-max-age"`
+
+	// OwnerCleanupHardDelete removes the images of deleted products and discarded drafts
+	// right away instead of moving them to the trash
+	OwnerCleanupHardDelete bool `mapstructure:"owner-cleanup-hard-delete"`
+}
+
+// TrashRetention holds the trash retention in days per owner type. Unset values take the
+// default; zero purges deleted images on the next run.
+type TrashRetention struct {
+	ProductDraft *int `mapstructure:"product-draft"`
+	Product      *int `mapstructure:"product"`
+	User         *int `mapstructure:"user"`
+}
+
+// DeliveryPreset is a named delivery transformation. Zero values are left to imgproxy.
+type DeliveryPreset struct {
+	Width    int    `mapstructure:"width"`
+	Height   int    `mapstructure:"height"`
+	Fit      string `mapstructure:"fit"`
+	Quality  int    `mapstructure:"quality"`
+	Format   string `mapstructure:"format"`
+	Imgproxy string `mapstructure:"imgproxy"` // name of a preset defined in imgproxy (IMGPROXY_PRESETS)
 }
 
 // NewConfig creates a new application config from Viper
@@ -30,6 +125,148 @@ func NewConfig(v *viper.Viper) (Config, error) {
 	if cfg.MaxUploadBytes == 0 {
 		cfg.MaxUploadBytes = 5 * 1024 * 1024 // 5 MB default
 	}
+	if cfg.MaxMultipartUploadBytes == 0 {
+		cfg.MaxMultipartUploadBytes = 100 * 1024 * 1024 // 100 MB default
+	}
+	if cfg.MaxAvatarBytes == 0 {
+		cfg.MaxAvatarBytes = 1024 * 1024 // 1 MB default
+	}
+	if cfg.MaxPixels == 0 {
+		cfg.MaxPixels = 50_000_000 // 50 MP default
+	}
+	if cfg.DuplicatePolicy == "" {
+		cfg.DuplicatePolicy = processing.DuplicateActionFlag
+	}
+	cfg.DuplicateMaxDistance = defaultInt(cfg.DuplicateMaxDistance, 4)
+	if err := cfg.Duplicates().Validate(); err != nil {
+		return cfg, fmt.Errorf("failed to load application config: %w", err)
+	}
+	if cfg.MultipartPartSize == 0 {
+		cfg.MultipartPartSize = 8 * 1024 * 1024 // 8 MB default, S3 requires at least 5 MB
+	}
+	if cfg.MultipartStaleAfter == 0 {
+		cfg.MultipartStaleAfter = 24 * time.Hour
+	}
+	if cfg.MultipartCleanupInterval == 0 {
+		cfg.MultipartCleanupInterval = time.Hour
+	}
+	if cfg.OrphanGracePeriod == 0 {
+		cfg.OrphanGracePeriod = 48 * time.Hour // longer than the presign TTL and a stuck promotion
+	}
+	if cfg.OrphanReapInterval == 0 {
+		cfg.OrphanReapInterval = 6 * time.Hour
+	}
+	cfg.TrashRetentionDays.ProductDraft = defaultInt(cfg.TrashRetentionDays.ProductDraft, 7)
+	cfg.TrashRetentionDays.Product = defaultInt(cfg.TrashRetentionDays.Product, 30)
+	cfg.TrashRetentionDays.User = defaultInt(cfg.TrashRetentionDays.User, 30)
+	if err := cfg.TrashRetentionDays.validate(); err != nil {
+		return cfg, fmt.Errorf("failed to load application config: %w", err)
+	}
+	if cfg.TrashPurgeInterval == 0 {
+		cfg.TrashPurgeInterval = time.Hour
+	}
+	if len(cfg.ImageRoles) == 0 {
+		cfg.ImageRoles = []string{"main", "gallery", "avatar"}
+	}
+	if len(cfg.SrcsetWidths) == 0 {
+		cfg.SrcsetWidths = []int{320, 640, 960, 1280, 1920}
+	}
+	if len(cfg.SrcsetFormats) == 0 {
+		cfg.SrcsetFormats = []string{"avif", "webp", "jpeg"}
+	}
+	if cfg.RenderCacheMaxAge == 0 {
+		cfg.RenderCacheMaxAge = time.Hour
+	}
+	if cfg.PromotionResumeAfter == 0 {
+		cfg.PromotionResumeAfter = 5 * time.Minute
+	}
+	if cfg.ProcessingInterval == 0 {
+		cfg.ProcessingInterval = 30 * time.Second
+	}
+	if cfg.ProcessingMaxAttempts == 0 {
+		cfg.ProcessingMaxAttempts = 10
+	}
 
 	return cfg, nil
 }
+
+// UploadLimits returns the upload size limits enforced by command handlers
+func (c Config) UploadLimits() command.UploadLimits {
+	return command.UploadLimits{
+		MaxBytes:          c.MaxUploadBytes,
+		MaxMultipartBytes: c.MaxMultipartUploadBytes,
+		MaxAvatarBytes:    c.MaxAvatarBytes,
+		MaxPixels:         c.MaxPixels,
+	}
+}
+
+// Duplicates returns the policy for near-duplicate uploads
+func (c Config) Duplicates() processing.DuplicatePolicy {
+	return processing.DuplicatePolicy{
+		Action:      c.DuplicatePolicy,
+		MaxDistance: *c.DuplicateMaxDistance,
+	}
+}
+
+// Presets returns the delivery presets as signer options
+func (c Config) Presets() map[string]abstraction.SignerOptions {
+	presets := make(map[string]abstraction.SignerOptions, len(c.DeliveryPresets))
+	for name, p := range c.DeliveryPresets {
+		var opts abstraction.SignerOptions
+		if p.Width > 0 {
+			opts.Width = &p.Width
+		}
+		if p.Height > 0 {
+			opts.Height = &p.Height
+		}
+		if p.Fit != "" {
+			opts.Fit = &p.Fit
+		}
+		if p.Quality > 0 {
+			opts.Quality = &p.Quality
+		}
+		if p.Format != "" {
+			opts.Format = &p.Format
+		}
+		if p.Imgproxy != "" {
+			opts.Preset = &p.Imgproxy
+		}
+		presets[name] = opts
+	}
+	return presets
+}
+
+// Srcset returns the default policy of responsive image sets
+func (c Config) Srcset() query.SrcsetPolicy {
+	return query.SrcsetPolicy{
+		Widths:  c.SrcsetWidths,
+		Formats: c.SrcsetFormats,
+	}
+}
+
+// TrashRetentionByOwnerType returns the trash retention of each owner type
+func (c Config) TrashRetentionByOwnerType() map[string]time.Duration {
+	day := 24 * time.Hour
+	return map[string]time.Duration{
+		image.OwnerTypeProductDraft: time.Duration(*c.TrashRetentionDays.ProductDraft) * day,
+		image.OwnerTypeProduct:      time.Duration(*c.TrashRetentionDays.Product) * day,
+		image.OwnerTypeUser:         time.Duration(*c.TrashRetentionDays.User) * day,
+	}
+}
+
+func (r TrashRetention) validate() error {
+	for name, days := range map[string]*int{"product-draft": r.ProductDraft, "product": r.Product, "user": r.User} {
+		if *days < 0 {
+			return fmt.Errorf("trash retention of %s must not be negative, got %d", name, *days)
+		}
+	}
+	return nil
+}
+
+// defaultInt returns v, or def when it is not configured
+func defaultInt(v *int, def int) *int {
+	if v != nil {
+		return v
+	}
+	return &def
+}
@@ -3,6 +3,7 @@ package application
 import (
 	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
 	"github.com/Sokol111/ecommerce-image-service/internal/application/command"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/processing"
 	"github.com/Sokol111/ecommerce-image-service/internal/application/query"
 	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
 	"go.uber.org/fx"
@@ -15,19 +16,95 @@ func Module() fx.Option {
 		fx.Provide(
 			NewConfig,
 		),
+		// Processing pipeline
+		fx.Provide(
+			func(
+				repo image.Repository,
+				storage abstraction.ObjectStorage,
+				hasher abstraction.PerceptualHasher,
+				derivatives abstraction.DerivativeGenerator,
+				cfg Config,
+			) *processing.Pipeline {
+				return processing.NewPipeline(
+					processing.NewMetadataStep(storage),
+					processing.NewDimensionsStep(storage),
+					processing.NewValidationStep(max(cfg.MaxUploadBytes, cfg.MaxMultipartUploadBytes), cfg.MaxPixels),
+					processing.NewHashStep(hasher),
+					processing.NewDuplicatesStep(repo, cfg.Duplicates()),
+					processing.NewDerivativesStep(derivatives),
+				)
+			},
+		),
 		// Command handlers
 		fx.Provide(
-			command.NewCreatePresignHandler,
-			func(repo image.Repository, storage abstraction.ObjectStorage, cfg Config) command.ConfirmUploadCommandHandler {
-				return command.NewConfirmUploadHandler(repo, storage, cfg.MaxUploadBytes)
+			func(lc fx.Lifecycle) *command.BackgroundRunner {
+				background := command.NewBackgroundRunner()
+				lc.Append(fx.Hook{OnStop: background.Stop})
+				return background
+			},
+			func(presigner abstraction.Presigner, cfg Config) command.CreatePresignCommandHandler {
+				return command.NewCreatePresignHandler(presigner, cfg.UploadLimits())
 			},
+			func(
+				repo image.Repository,
+				storage abstraction.ObjectStorage,
+				hasher abstraction.PerceptualHasher,
+				cfg Config,
+			) command.ConfirmUploadCommandHandler {
+				return command.NewConfirmUploadHandler(repo, storage, hasher, cfg.UploadLimits(), cfg.Duplicates())
+			},
+			command.NewPromotionRunner,
 			command.NewPromoteImagesHandler,
+			command.NewStartPromotionHandler,
+			command.NewRetryPromotionHandler,
+			command.NewResumePromotionsHandler,
 			command.NewDeleteImageHandler,
+			func(repo image.Repository, cfg Config) command.UpdateImageCommandHandler {
+				return command.NewUpdateImageHandler(repo, cfg.ImageRoles)
+			},
+			func(
+				repo image.Repository,
+				pipeline *processing.Pipeline,
+				deleteImage command.DeleteImageCommandHandler,
+				cfg Config,
+			) command.ProcessImageCommandHandler {
+				return command.NewProcessImageHandler(repo, pipeline, deleteImage, cfg.ProcessingMaxAttempts)
+			},
+			command.NewProcessPendingImagesHandler,
+			func(presigner abstraction.Presigner, storage abstraction.ObjectStorage, cfg Config) command.CreateMultipartUploadCommandHandler {
+				return command.NewCreateMultipartUploadHandler(presigner, storage, cfg.MultipartPartSize, cfg.UploadLimits())
+			},
+			command.NewPresignUploadPartsHandler,
+			command.NewCompleteMultipartUploadHandler,
+			command.NewAbortMultipartUploadHandler,
+			command.NewAbortStaleMultipartUploadsHandler,
+			command.NewReapOrphanedUploadsHandler,
+			command.NewRestoreImageHandler,
+			command.NewPurgeDeletedImagesHandler,
+			command.NewSyncProductImagesHandler,
+			func(repo image.Repository, storage abstraction.ObjectStorage, cfg Config) command.DeleteOwnerImagesCommandHandler {
+				return command.NewDeleteOwnerImagesHandler(repo, storage, cfg.OwnerCleanupHardDelete)
+			},
 		),
 		// Query handlers
 		fx.Provide(
 			query.NewGetImageByIDHandler,
-			query.NewGetDeliveryURLHandler,
+			func(repo image.Repository, signer abstraction.ImgproxySigner, cfg Config) query.GetDeliveryURLQueryHandler {
+				return query.NewGetDeliveryURLHandler(repo, signer, cfg.Presets())
+			},
+			func(repo image.Repository, signer abstraction.ImgproxySigner, cfg Config) query.GetSrcsetQueryHandler {
+				return query.NewGetSrcsetHandler(repo, signer, cfg.Srcset())
+			},
+			func(repo image.Repository, signer abstraction.ImgproxySigner, cfg Config) query.GetRenderURLQueryHandler {
+				return query.NewGetRenderURLHandler(repo, signer, cfg.Srcset(), cfg.RenderCacheMaxAge)
+			},
+			query.NewListImagesHandler,
+			func(repo image.Repository, cfg Config) query.ListDuplicateClustersQueryHandler {
+				return query.NewListDuplicateClustersHandler(repo, cfg.Duplicates().MaxDistance)
+			},
+			query.NewGetPromotionJobHandler,
+			query.NewListPromotionJobsHandler,
+			query.NewVerifyDeliveryURLHandler,
 		),
 	)
 }
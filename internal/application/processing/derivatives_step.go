@@ -0,0 +1,33 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+type derivativesStep struct {
+	generator abstraction.DerivativeGenerator
+}
+
+// NewDerivativesStep renders the standard derivatives of the image
+func NewDerivativesStep(generator abstraction.DerivativeGenerator) Step {
+	return &derivativesStep{generator: generator}
+}
+
+func (s *derivativesStep) Name() string {
+	return "derivatives"
+}
+
+func (s *derivativesStep) Run(ctx context.Context, img *image.Image) error {
+	// Drafts are not delivered; their derivatives are rendered for the key they are promoted to
+	if img.OwnerType == image.OwnerTypeProductDraft {
+		return nil
+	}
+	if err := s.generator.Generate(ctx, img.Key); err != nil {
+		return fmt.Errorf("generate derivatives: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+type dimensionsStep struct {
+	objStorage abstraction.ObjectStorage
+}
+
+// NewDimensionsStep reads the pixel size of images confirmed before it was recorded on upload
+func NewDimensionsStep(storage abstraction.ObjectStorage) Step {
+	return &dimensionsStep{objStorage: storage}
+}
+
+func (s *dimensionsStep) Name() string {
+	return "dimensions"
+}
+
+func (s *dimensionsStep) Run(ctx context.Context, img *image.Image) error {
+	if img.HasDimensions() || img.Size == 0 {
+		return nil
+	}
+
+	out, err := s.objStorage.GetObjectRange(ctx, &abstraction.GetObjectRangeInput{
+		Key:    img.Key,
+		Offset: 0,
+		Length: min(img.Size, image.HeaderReadLength),
+	})
+	if err != nil {
+		return fmt.Errorf("read object header: %w", err)
+	}
+
+	dims, err := ReadObjectDimensions(ctx, s.objStorage, img.Key, img.Mime, img.Size, out.Body)
+	if err != nil {
+		return reject("%v", err)
+	}
+	img.RecordDimensions(dims)
+
+	return nil
+}
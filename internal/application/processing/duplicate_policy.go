@@ -0,0 +1,29 @@
+package processing
+
+import "fmt"
+
+// Actions taken on a near-duplicate upload
+const (
+	DuplicateActionOff    = "off"
+	DuplicateActionFlag   = "flag"
+	DuplicateActionReject = "reject"
+)
+
+// DuplicatePolicy decides what happens when an upload looks like an image its owner already has
+type DuplicatePolicy struct {
+	Action      string // off | flag | reject
+	MaxDistance int    // maximum number of differing perceptual hash bits
+}
+
+// Validate checks that the action is known and the distance fits a 64-bit hash
+func (p DuplicatePolicy) Validate() error {
+	switch p.Action {
+	case DuplicateActionOff, DuplicateActionFlag, DuplicateActionReject:
+	default:
+		return fmt.Errorf("unsupported duplicate action: %s", p.Action)
+	}
+	if p.MaxDistance < 0 || p.MaxDistance >= 64 {
+		return fmt.Errorf("duplicate max distance must be between 0 and 63: %d", p.MaxDistance)
+	}
+	return nil
+}
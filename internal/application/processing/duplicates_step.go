@@ -0,0 +1,58 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+type duplicatesStep struct {
+	repo   image.Repository
+	policy DuplicatePolicy
+}
+
+// NewDuplicatesStep rejects or flags images that look like another image of the same owner
+func NewDuplicatesStep(repo image.Repository, policy DuplicatePolicy) Step {
+	return &duplicatesStep{
+		repo:   repo,
+		policy: policy,
+	}
+}
+
+func (s *duplicatesStep) Name() string {
+	return "duplicates"
+}
+
+func (s *duplicatesStep) Run(ctx context.Context, img *image.Image) error {
+	// Avatars replace each other, so a repeated upload is expected.
+	// An image flagged before its promotion keeps its flag.
+	if s.policy.Action == DuplicateActionOff || img.OwnerType == image.OwnerTypeUser ||
+		img.PerceptualHash == "" || img.DuplicateOf != "" {
+		return nil
+	}
+
+	existing, err := s.repo.FindByOwner(ctx, img.OwnerType, img.OwnerID, nil)
+	if err != nil {
+		return fmt.Errorf("find owner images: %w", err)
+	}
+
+	// Images rejected earlier are not originals to compare with
+	candidates := existing[:0]
+	for _, c := range existing {
+		if c.Status != image.StatusFailed {
+			candidates = append(candidates, c)
+		}
+	}
+
+	dup := image.FindNearDuplicate(img, candidates, s.policy.MaxDistance)
+	if dup == nil {
+		return nil
+	}
+
+	if s.policy.Action == DuplicateActionReject {
+		return fmt.Errorf("%w: %w: looks like image %s", image.ErrImageRejected, image.ErrDuplicateImage, dup.ID)
+	}
+	img.FlagAsDuplicateOf(dup.ID)
+	return nil
+}
@@ -0,0 +1,79 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// ownerImages is an image.Repository that only lists the images of an owner
+type ownerImages struct {
+	image.Repository
+	images []*image.Image
+}
+
+func (r *ownerImages) FindByOwner(_ context.Context, ownerType, ownerID string, _ []string) ([]*image.Image, error) {
+	var out []*image.Image
+	for _, img := range r.images {
+		if img.OwnerType == ownerType && img.OwnerID == ownerID {
+			out = append(out, img)
+		}
+	}
+	return out, nil
+}
+
+func hashedImage(t *testing.T, id, ownerType, hash string) *image.Image {
+	img, err := image.NewImageWithID(id, "", ownerType, "o-1", "gallery", "products/o-1/"+id+".jpg", "image/jpeg", 10)
+	if err != nil {
+		t.Fatalf("NewImageWithID: %v", err)
+	}
+	img.RecordPerceptualHash(hash)
+	return img
+}
+
+func TestDuplicatesStep(t *testing.T) {
+	original := hashedImage(t, "original", image.OwnerTypeProduct, "00000000000000ff")
+	rejected := hashedImage(t, "rejected", image.OwnerTypeProduct, "ff000000000000ff")
+	rejected.MarkAsFailed("duplicate")
+
+	tests := []struct {
+		name        string
+		action      string
+		ownerType   string
+		hash        string
+		wantErr     error
+		wantFlagged string
+	}{
+		{"flags a near-duplicate", DuplicateActionFlag, image.OwnerTypeProduct, "00000000000000fe", nil, "original"},
+		{"rejects a near-duplicate", DuplicateActionReject, image.OwnerTypeProduct, "00000000000000fe", image.ErrDuplicateImage, ""},
+		{"ignores distant images", DuplicateActionReject, image.OwnerTypeProduct, "0f0f0f0f0f0f0f0f", nil, ""},
+		{"ignores rejected images", DuplicateActionReject, image.OwnerTypeProduct, "ff000000000000fe", nil, ""},
+		{"ignores avatars", DuplicateActionReject, image.OwnerTypeUser, "00000000000000fe", nil, ""},
+		{"does nothing when off", DuplicateActionOff, image.OwnerTypeProduct, "00000000000000fe", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &ownerImages{images: []*image.Image{original, rejected}}
+			step := NewDuplicatesStep(repo, DuplicatePolicy{Action: tt.action, MaxDistance: 2})
+
+			img := hashedImage(t, "new", tt.ownerType, tt.hash)
+			err := step.Run(context.Background(), img)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || !errors.Is(err, image.ErrImageRejected) {
+					t.Fatalf("err = %v, want a rejection for %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if img.DuplicateOf != tt.wantFlagged {
+				t.Errorf("DuplicateOf = %q, want %q", img.DuplicateOf, tt.wantFlagged)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+type hashStep struct {
+	hasher abstraction.PerceptualHasher
+}
+
+// NewHashStep computes the perceptual hash of images confirmed without one
+func NewHashStep(hasher abstraction.PerceptualHasher) Step {
+	return &hashStep{hasher: hasher}
+}
+
+func (s *hashStep) Name() string {
+	return "hash"
+}
+
+func (s *hashStep) Run(ctx context.Context, img *image.Image) error {
+	if img.PerceptualHash != "" {
+		return nil
+	}
+
+	hash, err := s.hasher.Hash(ctx, img.Key)
+	if err != nil {
+		return fmt.Errorf("perceptual hash: %w", err)
+	}
+	img.RecordPerceptualHash(hash)
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+type metadataStep struct {
+	objStorage abstraction.ObjectStorage
+}
+
+// NewMetadataStep reads the stored object metadata and records it on the image
+func NewMetadataStep(storage abstraction.ObjectStorage) Step {
+	return &metadataStep{objStorage: storage}
+}
+
+func (s *metadataStep) Name() string {
+	return "metadata"
+}
+
+func (s *metadataStep) Run(ctx context.Context, img *image.Image) error {
+	ho, err := s.objStorage.HeadObject(ctx, &abstraction.HeadObjectInput{
+		Key: img.Key,
+	})
+	if err != nil {
+		return fmt.Errorf("head object: %w", err)
+	}
+
+	if ho.ContentLength != nil && *ho.ContentLength != img.Size {
+		img.RecordSize(*ho.ContentLength)
+	}
+
+	return nil
+}
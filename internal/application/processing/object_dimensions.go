@@ -0,0 +1,50 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// ReadObjectDimensions reads the pixel size of a stored image from its leading bytes.
+// A JPEG whose frame header lies past them is scanned further with ranged reads.
+func ReadObjectDimensions(ctx context.Context, storage abstraction.ObjectStorage, key, mime string, size int64, head []byte) (image.Dimensions, error) {
+	dims, err := image.ReadDimensions(head, mime)
+	if err == nil || mime != "image/jpeg" || int64(len(head)) >= size {
+		return dims, err
+	}
+
+	return image.ReadJPEGDimensionsAt(&objectReader{ctx: ctx, storage: storage, key: key, head: head}, size)
+}
+
+// objectReader reads a stored object with ranged reads, serving the already read head from memory
+type objectReader struct {
+	ctx     context.Context
+	storage abstraction.ObjectStorage
+	key     string
+	head    []byte
+}
+
+func (r *objectReader) ReadAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) <= int64(len(r.head)) {
+		return copy(p, r.head[off:]), nil
+	}
+
+	out, err := r.storage.GetObjectRange(r.ctx, &abstraction.GetObjectRangeInput{
+		Key:    r.key,
+		Offset: off,
+		Length: int64(len(p)),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("read object range: %w", err)
+	}
+
+	n := copy(p, out.Body)
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,66 @@
+package processing
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	stdimage "image"
+	"image/jpeg"
+	"testing"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// rangeStorage serves ranged reads of a single object and counts them
+type rangeStorage struct {
+	abstraction.ObjectStorage
+	data  []byte
+	reads int
+}
+
+func (s *rangeStorage) GetObjectRange(_ context.Context, input *abstraction.GetObjectRangeInput) (*abstraction.GetObjectRangeOutput, error) {
+	s.reads++
+	end := min(input.Offset+input.Length, int64(len(s.data)))
+	return &abstraction.GetObjectRangeOutput{Body: s.data[input.Offset:end]}, nil
+}
+
+func TestReadObjectDimensionsScansLargeJPEGMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, stdimage.NewGray(stdimage.Rect(0, 0, 12, 7)), nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	encoded := buf.Bytes()
+
+	// ICC profiles and XMP can add up to several hundred KB of APP segments
+	data := append([]byte{}, encoded[:2]...)
+	for range 6 {
+		data = append(data, 0xff, 0xe2)
+		data = binary.BigEndian.AppendUint16(data, 0xfff2)
+		data = append(data, make([]byte, 0xfff0)...)
+	}
+	data = append(data, encoded[2:]...)
+
+	storage := &rangeStorage{data: data}
+	head := data[:64*1024]
+
+	dims, err := ReadObjectDimensions(context.Background(), storage, "products/p-1/a.jpg", "image/jpeg", int64(len(data)), head)
+	if err != nil {
+		t.Fatalf("ReadObjectDimensions: %v", err)
+	}
+	if dims.Width != 12 || dims.Height != 7 {
+		t.Errorf("dims = %dx%d, want 12x7", dims.Width, dims.Height)
+	}
+	if storage.reads > 2 {
+		t.Errorf("%d ranged reads, want at most 2", storage.reads)
+	}
+}
+
+func TestReadObjectDimensionsKeepsHeaderErrors(t *testing.T) {
+	storage := &rangeStorage{data: []byte("not an image")}
+	_, err := ReadObjectDimensions(context.Background(), storage, "k", "image/png", 12, storage.data)
+	if !errors.Is(err, image.ErrUnreadableDimensions) || storage.reads != 0 {
+		t.Fatalf("err = %v after %d reads, want a header error without reads", err, storage.reads)
+	}
+}
@@ -0,0 +1,39 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// Step is a single post-upload step of the processing pipeline.
+// A step that finds the image unusable returns an error wrapping image.ErrImageRejected;
+// any other error is treated as transient and the image can be processed again.
+type Step interface {
+	Name() string
+	Run(ctx context.Context, img *image.Image) error
+}
+
+// Pipeline runs the post-upload steps in order
+type Pipeline struct {
+	steps []Step
+}
+
+func NewPipeline(steps ...Step) *Pipeline {
+	return &Pipeline{steps: steps}
+}
+
+// Run executes the steps in order and stops at the first error
+func (p *Pipeline) Run(ctx context.Context, img *image.Image) error {
+	for _, step := range p.steps {
+		if err := step.Run(ctx, img); err != nil {
+			return fmt.Errorf("%s: %w", step.Name(), err)
+		}
+	}
+	return nil
+}
+
+func reject(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", image.ErrImageRejected, fmt.Sprintf(format, args...))
+}
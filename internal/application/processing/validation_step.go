@@ -0,0 +1,44 @@
+package processing
+
+import (
+	"context"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+type validationStep struct {
+	maxUploadBytes int64
+	maxPixels      int64
+}
+
+// NewValidationStep checks the image against the upload limits
+func NewValidationStep(maxUploadBytes, maxPixels int64) Step {
+	return &validationStep{
+		maxUploadBytes: maxUploadBytes,
+		maxPixels:      maxPixels,
+	}
+}
+
+func (s *validationStep) Name() string {
+	return "validation"
+}
+
+func (s *validationStep) Run(_ context.Context, img *image.Image) error {
+	if image.ExtensionForMime(img.Mime) == "" {
+		return reject("unsupported mime type %s", img.Mime)
+	}
+	if img.DetectedMime != "" && img.DetectedMime != img.Mime {
+		return reject("declared mime type %s, detected %s", img.Mime, img.DetectedMime)
+	}
+	if img.Size == 0 {
+		return reject("empty file")
+	}
+	if s.maxUploadBytes > 0 && img.Size > s.maxUploadBytes {
+		return reject("file too large: %d bytes, max %d bytes", img.Size, s.maxUploadBytes)
+	}
+	dims := image.Dimensions{Width: img.Width, Height: img.Height}
+	if err := image.ValidatePixelCount(dims, s.maxPixels); err != nil {
+		return reject("%v", err)
+	}
+	return nil
+}
@@ -2,10 +2,13 @@ package query
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
 	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
 	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
 	"go.uber.org/zap"
@@ -14,6 +17,9 @@ import (
 // GetDeliveryURLQuery represents a query to get a delivery URL for an image
 type GetDeliveryURLQuery struct {
 	ImageID string
+	Preset  *string // named preset; replaces the size, fit, quality and format below
+	Aspect  *string // "W:H"; selects the stored crop of that ratio
+	Caller  string  // authenticated client; empty for anonymous requests
 	Width   *int
 	Height  *int
 	Fit     *string
@@ -21,6 +27,19 @@ type GetDeliveryURLQuery struct {
 	DPR     *float32
 	Format  *string
 	Expires *time.Time
+
+	Gravity           *abstraction.Gravity
+	Crop              *abstraction.Crop
+	Padding           *abstraction.Padding
+	Background        *string
+	Rotate            *int
+	Flip              *abstraction.Flip
+	Blur              *float32
+	Sharpen           *float32
+	Trim              *float32
+	StripMetadata     *bool
+	StripColorProfile *bool
+	CacheBuster       *string
 }
 
 // GetDeliveryURLResult represents the result of getting a delivery URL
@@ -35,34 +54,47 @@ type GetDeliveryURLQueryHandler interface {
 }
 
 type getDeliveryURLHandler struct {
-	repo   image.Repository
-	signer abstraction.ImgproxySigner
+	repo    image.Repository
+	signer  abstraction.ImgproxySigner
+	presets map[string]abstraction.SignerOptions
 }
 
-func NewGetDeliveryURLHandler(repo image.Repository, signer abstraction.ImgproxySigner) GetDeliveryURLQueryHandler {
+func NewGetDeliveryURLHandler(repo image.Repository, signer abstraction.ImgproxySigner, presets map[string]abstraction.SignerOptions) GetDeliveryURLQueryHandler {
 	return &getDeliveryURLHandler{
-		repo:   repo,
-		signer: signer,
+		repo:    repo,
+		signer:  signer,
+		presets: presets,
 	}
 }
 
 func (h *getDeliveryURLHandler) Handle(ctx context.Context, query GetDeliveryURLQuery) (*GetDeliveryURLResult, error) {
+	opts, err := h.signerOptions(query)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get image from repository
 	img, err := h.repo.FindByID(ctx, query.ImageID)
 	if err != nil {
+		if errors.Is(err, persistence.ErrEntityNotFound) {
+			return nil, image.ErrImageNotFound
+		}
 		return nil, fmt.Errorf("failed to get image by id: %w", err)
 	}
 
+	// Images in the trash keep their object but are not delivered
+	if img.IsDeleted() {
+		return nil, image.ErrImageNotFound
+	}
+
+	opts.OwnerType = img.OwnerType
+	opts.Caller = query.Caller
+	if err := applyEdits(img, query, &opts); err != nil {
+		return nil, err
+	}
+
 	// Build imgproxy URL (infrastructure layer handles S3 source formatting)
-	imgproxyURL := h.signer.BuildURL(img.Key, abstraction.SignerOptions{
-		Width:   query.Width,
-		Height:  query.Height,
-		Fit:     query.Fit,
-		Quality: query.Quality,
-		DPR:     query.DPR,
-		Format:  query.Format,
-		Expires: query.Expires,
-	})
+	imgproxyURL := h.signer.BuildURL(img.Key, opts)
 
 	h.log(ctx).Debug("delivery URL generated", zap.String("imageID", query.ImageID))
 
@@ -72,6 +104,93 @@ func (h *getDeliveryURLHandler) Handle(ctx context.Context, query GetDeliveryURL
 	}, nil
 }
 
+// signerOptions resolves the preset of the query. Only the pixel ratio and the expiry
+// are taken from the caller then, so every client of a preset gets the same variant.
+func (h *getDeliveryURLHandler) signerOptions(query GetDeliveryURLQuery) (abstraction.SignerOptions, error) {
+	if query.Preset == nil || *query.Preset == "" {
+		opts := abstraction.SignerOptions{
+			Width:             query.Width,
+			Height:            query.Height,
+			Fit:               query.Fit,
+			Quality:           query.Quality,
+			DPR:               query.DPR,
+			Format:            query.Format,
+			Expires:           query.Expires,
+			Gravity:           query.Gravity,
+			Crop:              query.Crop,
+			Padding:           query.Padding,
+			Background:        query.Background,
+			Rotate:            query.Rotate,
+			Flip:              query.Flip,
+			Blur:              query.Blur,
+			Sharpen:           query.Sharpen,
+			Trim:              query.Trim,
+			StripMetadata:     query.StripMetadata,
+			StripColorProfile: query.StripColorProfile,
+			CacheBuster:       query.CacheBuster,
+		}
+		return opts, validateProcessing(opts)
+	}
+
+	opts, ok := h.presets[*query.Preset]
+	if !ok {
+		return abstraction.SignerOptions{}, fmt.Errorf("%w: %s", image.ErrUnknownPreset, *query.Preset)
+	}
+	opts.DPR = query.DPR
+	opts.Expires = query.Expires
+	opts.DeliveryPreset = *query.Preset
+	return opts, nil
+}
+
+// applyEdits applies the stored crop matching the preset or aspect, falling back to the
+// focal point as gravity. Explicit crop and gravity options of the caller win.
+func applyEdits(img *image.Image, query GetDeliveryURLQuery, opts *abstraction.SignerOptions) error {
+	var ratio float64
+	if query.Aspect != nil && *query.Aspect != "" {
+		r, ok := image.ParseAspectRatio(*query.Aspect)
+		if !ok {
+			return fmt.Errorf("%w: invalid aspect %q", image.ErrInvalidDeliveryPolicy, *query.Aspect)
+		}
+		ratio = r
+	}
+
+	var crop *image.Crop
+	if query.Preset != nil && *query.Preset != "" {
+		crop = img.CropNamed(*query.Preset)
+	}
+	if crop == nil && ratio > 0 {
+		crop = img.CropForAspect(*query.Aspect)
+	}
+
+	if crop != nil && opts.Crop == nil && img.DisplayWidth > 0 && img.DisplayHeight > 0 {
+		w, h := float64(img.DisplayWidth), float64(img.DisplayHeight)
+		opts.Crop = &abstraction.Crop{
+			Width:  crop.Width * w,
+			Height: crop.Height * h,
+			Gravity: &abstraction.Gravity{
+				Type: abstraction.GravityNorthWest,
+				X:    crop.X * w,
+				Y:    crop.Y * h,
+			},
+		}
+	} else if img.FocalPoint != nil && opts.Gravity == nil {
+		opts.Gravity = &abstraction.Gravity{
+			Type: abstraction.GravityFocalPoint,
+			X:    img.FocalPoint.X,
+			Y:    img.FocalPoint.Y,
+		}
+	}
+
+	// Without a stored crop the aspect is honoured by filling around the gravity
+	if crop == nil && ratio > 0 && opts.Width != nil && opts.Height == nil {
+		height := int(math.Round(float64(*opts.Width) / ratio))
+		fit := "fill"
+		opts.Height = &height
+		opts.Fit = &fit
+	}
+	return nil
+}
+
 func (h *getDeliveryURLHandler) log(ctx context.Context) *zap.Logger {
 	return logger.FromContext(ctx).With(zap.String("component", "get-delivery-url-handler"))
 }
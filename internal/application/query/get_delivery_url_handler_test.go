@@ -0,0 +1,97 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+func TestGetDeliveryURLPresets(t *testing.T) {
+	cardWidth, cardQuality, fill := 400, 70, "fill"
+	presets := map[string]abstraction.SignerOptions{
+		"card": {Width: &cardWidth, Fit: &fill, Quality: &cardQuality},
+	}
+	card, missing := "card", "poster"
+	width, dpr := 1200, float32(2)
+	expires := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		query       GetDeliveryURLQuery
+		wantErr     error
+		wantWidth   int
+		wantPreset  string
+		wantQuality bool
+	}{
+		{
+			name:      "without preset the caller's size is used",
+			query:     GetDeliveryURLQuery{Width: &width},
+			wantWidth: 1200,
+		},
+		{
+			name:        "preset replaces the caller's size",
+			query:       GetDeliveryURLQuery{Preset: &card, Width: &width, DPR: &dpr, Expires: &expires},
+			wantWidth:   400,
+			wantPreset:  "card",
+			wantQuality: true,
+		},
+		{
+			name:    "unknown preset",
+			query:   GetDeliveryURLQuery{Preset: &missing},
+			wantErr: image.ErrUnknownPreset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := &recordingSigner{}
+			repo := &singleImageRepo{img: newRenderTestImage(t, "image/jpeg", 0)}
+			handler := NewGetDeliveryURLHandler(repo, signer, presets)
+
+			tt.query.ImageID = "img-1"
+			_, err := handler.Handle(context.Background(), tt.query)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+
+			got := signer.opts
+			if got.Width == nil || *got.Width != tt.wantWidth {
+				t.Fatalf("width = %v, want %d", got.Width, tt.wantWidth)
+			}
+			if got.DeliveryPreset != tt.wantPreset {
+				t.Fatalf("delivery preset = %q, want %q", got.DeliveryPreset, tt.wantPreset)
+			}
+			if (got.Quality != nil) != tt.wantQuality {
+				t.Fatalf("quality = %v", got.Quality)
+			}
+			if got.DPR != tt.query.DPR || got.Expires != tt.query.Expires {
+				t.Fatal("pixel ratio and expiry are not the caller's")
+			}
+		})
+	}
+}
+
+func TestGetDeliveryURLPresetsAreNotShared(t *testing.T) {
+	width := 400
+	presets := map[string]abstraction.SignerOptions{"card": {Width: &width}}
+	card, dpr := "card", float32(3)
+
+	handler := NewGetDeliveryURLHandler(&singleImageRepo{img: newRenderTestImage(t, "image/jpeg", 0)}, &recordingSigner{}, presets)
+	if _, err := handler.Handle(context.Background(), GetDeliveryURLQuery{ImageID: "img-1", Preset: &card, DPR: &dpr}); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	if p := presets["card"]; p.DPR != nil || p.DeliveryPreset != "" {
+		t.Fatalf("request changed the preset: %+v", p)
+	}
+}
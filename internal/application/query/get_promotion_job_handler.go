@@ -0,0 +1,39 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/promotion"
+)
+
+// GetPromotionJobQuery represents a query to get a promotion job by ID
+type GetPromotionJobQuery struct {
+	ID string
+}
+
+// GetPromotionJobQueryHandler handles GetPromotionJobQuery
+type GetPromotionJobQueryHandler interface {
+	Handle(ctx context.Context, query GetPromotionJobQuery) (*promotion.Job, error)
+}
+
+type getPromotionJobHandler struct {
+	jobs promotion.Repository
+}
+
+func NewGetPromotionJobHandler(jobs promotion.Repository) GetPromotionJobQueryHandler {
+	return &getPromotionJobHandler{jobs: jobs}
+}
+
+func (h *getPromotionJobHandler) Handle(ctx context.Context, query GetPromotionJobQuery) (*promotion.Job, error) {
+	job, err := h.jobs.FindByID(ctx, query.ID)
+	if err != nil {
+		if errors.Is(err, persistence.ErrEntityNotFound) {
+			return nil, promotion.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get promotion job: %w", err)
+	}
+	return job, nil
+}
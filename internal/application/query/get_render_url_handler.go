@@ -0,0 +1,177 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"go.uber.org/zap"
+)
+
+const (
+	maxRenderDPR    = 3
+	saveDataQuality = 50
+	saveDataMaxDPR  = 1
+)
+
+// GetRenderURLQuery represents a request for the delivery URL that best suits a client,
+// as described by its Accept header and client hints
+type GetRenderURLQuery struct {
+	ImageID     string
+	AcceptsAVIF bool
+	AcceptsWebP bool
+	DPR         float32 // Sec-CH-DPR; zero when not sent
+	Width       int     // Sec-CH-Width in physical pixels; zero when not sent
+	LayoutWidth int     // width in CSS pixels requested by the page; zero when not given
+	SaveData    bool
+	Fit         *string
+	Caller      string // authenticated client; empty for anonymous requests
+}
+
+// GetRenderURLResult represents the chosen variant
+type GetRenderURLResult struct {
+	URL    string
+	Format string
+	Width  int
+	MaxAge time.Duration // how long the choice may be cached
+}
+
+// GetRenderURLQueryHandler handles GetRenderURLQuery
+type GetRenderURLQueryHandler interface {
+	Handle(ctx context.Context, query GetRenderURLQuery) (*GetRenderURLResult, error)
+}
+
+type getRenderURLHandler struct {
+	repo   image.Repository
+	signer abstraction.ImgproxySigner
+	policy SrcsetPolicy
+	maxAge time.Duration
+}
+
+// NewGetRenderURLHandler creates the handler. Requested sizes are rounded up to the
+// srcset breakpoints, so that the rendered variants are shared with srcset clients.
+func NewGetRenderURLHandler(repo image.Repository, signer abstraction.ImgproxySigner, policy SrcsetPolicy, maxAge time.Duration) GetRenderURLQueryHandler {
+	widths := slices.Clone(policy.Widths)
+	slices.Sort(widths)
+	policy.Widths = slices.Compact(widths)
+
+	return &getRenderURLHandler{
+		repo:   repo,
+		signer: signer,
+		policy: policy,
+		maxAge: maxAge,
+	}
+}
+
+func (h *getRenderURLHandler) Handle(ctx context.Context, query GetRenderURLQuery) (*GetRenderURLResult, error) {
+	if err := validateProcessing(abstraction.SignerOptions{Fit: query.Fit}); err != nil {
+		return nil, err
+	}
+
+	img, err := h.repo.FindByID(ctx, query.ImageID)
+	if err != nil {
+		if errors.Is(err, persistence.ErrEntityNotFound) {
+			return nil, image.ErrImageNotFound
+		}
+		return nil, fmt.Errorf("failed to get image by id: %w", err)
+	}
+
+	// Images in the trash keep their object but are not delivered
+	if img.IsDeleted() {
+		return nil, image.ErrImageNotFound
+	}
+
+	format := h.format(query, img)
+	width := h.width(query, img.DisplayWidth)
+
+	opts := abstraction.SignerOptions{
+		Fit:       query.Fit,
+		Format:    &format,
+		OwnerType: img.OwnerType,
+		Caller:    query.Caller,
+	}
+	if width > 0 {
+		opts.Width = &width
+	}
+	if query.SaveData {
+		quality := saveDataQuality
+		opts.Quality = &quality
+	}
+
+	h.log(ctx).Debug("render variant chosen", zap.String("imageID", query.ImageID), zap.String("format", format), zap.Int("width", width))
+
+	return &GetRenderURLResult{
+		URL:    h.signer.BuildURL(img.Key, opts),
+		Format: format,
+		Width:  width,
+		MaxAge: h.maxAge,
+	}, nil
+}
+
+// format picks the most efficient format the client accepts. Images that may be
+// transparent stay PNG for clients without a modern format.
+func (h *getRenderURLHandler) format(query GetRenderURLQuery, img *image.Image) string {
+	switch {
+	case query.AcceptsAVIF:
+		return "avif"
+	case query.AcceptsWebP:
+		return "webp"
+	case img.Mime == "image/png":
+		return "png"
+	default:
+		return "jpeg"
+	}
+}
+
+// width is the pixel width to render: the hinted or requested width rounded up to the next
+// breakpoint, never wider than the largest breakpoint or the image itself, so that clients
+// cannot make up new variants. Zero keeps the original width.
+func (h *getRenderURLHandler) width(query GetRenderURLQuery, intrinsic int) int {
+	target := query.Width
+	if target <= 0 && query.LayoutWidth > 0 {
+		dpr := float64(query.DPR)
+		if dpr <= 0 {
+			dpr = 1
+		}
+		maxDPR := float64(maxRenderDPR)
+		if query.SaveData {
+			maxDPR = saveDataMaxDPR
+		}
+		target = int(math.Ceil(float64(query.LayoutWidth) * min(dpr, maxDPR)))
+	}
+
+	widths := h.policy.Widths
+	if target <= 0 {
+		// Without hints the largest breakpoint serves every layout
+		if len(widths) == 0 {
+			return 0
+		}
+		target = widths[len(widths)-1]
+	}
+
+	for _, w := range widths {
+		if w >= target {
+			target = w
+			break
+		}
+	}
+	if len(widths) > 0 {
+		target = min(target, widths[len(widths)-1])
+	}
+
+	if intrinsic > 0 && target > intrinsic {
+		return intrinsic
+	}
+	return target
+}
+
+func (h *getRenderURLHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "get-render-url-handler"))
+}
@@ -0,0 +1,159 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// singleImageRepo serves one image by ID
+type singleImageRepo struct {
+	image.Repository
+	img *image.Image
+}
+
+func (r *singleImageRepo) FindByID(_ context.Context, id string) (*image.Image, error) {
+	if r.img == nil || r.img.ID != id {
+		return nil, persistence.ErrEntityNotFound
+	}
+	return r.img, nil
+}
+
+// recordingSigner captures the options of the last URL it built
+type recordingSigner struct {
+	abstraction.ImgproxySigner
+	opts abstraction.SignerOptions
+}
+
+func (s *recordingSigner) BuildURL(key string, opts abstraction.SignerOptions) string {
+	s.opts = opts
+	return "https://imgproxy/" + key
+}
+
+func newRenderTestImage(t *testing.T, mime string, width int) *image.Image {
+	t.Helper()
+	img, err := image.NewImageWithID("img-1", "", image.OwnerTypeProduct, "product-1", "gallery", "products/product-1/img-1.jpg", mime, 100)
+	if err != nil {
+		t.Fatalf("new image: %v", err)
+	}
+	if width > 0 {
+		img.RecordDimensions(image.Dimensions{Width: width, Height: width})
+	}
+	return img
+}
+
+func TestGetRenderURL(t *testing.T) {
+	fill, bogus := "fill", "stretch"
+
+	tests := []struct {
+		name       string
+		query      GetRenderURLQuery
+		mime       string
+		intrinsic  int
+		wantErr    error
+		wantFormat string
+		wantWidth  int
+	}{
+		{
+			name:       "without hints the largest breakpoint is used",
+			query:      GetRenderURLQuery{},
+			wantFormat: "jpeg",
+			wantWidth:  1280,
+		},
+		{
+			name:       "hinted width is rounded up to a breakpoint",
+			query:      GetRenderURLQuery{Width: 500},
+			wantFormat: "jpeg",
+			wantWidth:  640,
+		},
+		{
+			name:       "layout width is scaled by the capped DPR",
+			query:      GetRenderURLQuery{LayoutWidth: 200, DPR: 5},
+			wantFormat: "jpeg",
+			wantWidth:  640,
+		},
+		{
+			name:       "width beyond the breakpoints of an image of unknown size is clamped",
+			query:      GetRenderURLQuery{Width: 100000},
+			wantFormat: "jpeg",
+			wantWidth:  1280,
+		},
+		{
+			name:       "width is never above the intrinsic width",
+			query:      GetRenderURLQuery{Width: 1000},
+			intrinsic:  900,
+			wantFormat: "jpeg",
+			wantWidth:  900,
+		},
+		{
+			name:       "AVIF is preferred over WebP",
+			query:      GetRenderURLQuery{AcceptsAVIF: true, AcceptsWebP: true},
+			wantFormat: "avif",
+			wantWidth:  1280,
+		},
+		{
+			name:       "PNG stays PNG without a modern format",
+			query:      GetRenderURLQuery{},
+			mime:       "image/png",
+			wantFormat: "png",
+			wantWidth:  1280,
+		},
+		{
+			name:       "known fit is passed on",
+			query:      GetRenderURLQuery{Fit: &fill},
+			wantFormat: "jpeg",
+			wantWidth:  1280,
+		},
+		{
+			name:    "unknown fit is rejected",
+			query:   GetRenderURLQuery{Fit: &bogus},
+			wantErr: image.ErrInvalidDeliveryPolicy,
+		},
+		{
+			name:    "unknown image",
+			query:   GetRenderURLQuery{ImageID: "other"},
+			wantErr: image.ErrImageNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mime := tt.mime
+			if mime == "" {
+				mime = "image/jpeg"
+			}
+			signer := &recordingSigner{}
+			repo := &singleImageRepo{img: newRenderTestImage(t, mime, tt.intrinsic)}
+			handler := NewGetRenderURLHandler(repo, signer, SrcsetPolicy{Widths: []int{1280, 320, 640, 640}}, time.Minute)
+
+			if tt.query.ImageID == "" {
+				tt.query.ImageID = "img-1"
+			}
+			result, err := handler.Handle(context.Background(), tt.query)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+
+			if result.Format != tt.wantFormat || result.Width != tt.wantWidth {
+				t.Errorf("variant = %s %d, want %s %d", result.Format, result.Width, tt.wantFormat, tt.wantWidth)
+			}
+			if signer.opts.Width == nil || *signer.opts.Width != tt.wantWidth {
+				t.Errorf("signed width = %v, want %d", signer.opts.Width, tt.wantWidth)
+			}
+			if tt.query.Fit != nil && (signer.opts.Fit == nil || *signer.opts.Fit != *tt.query.Fit) {
+				t.Errorf("signed fit = %v, want %s", signer.opts.Fit, *tt.query.Fit)
+			}
+		})
+	}
+}
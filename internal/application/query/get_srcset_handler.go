@@ -0,0 +1,211 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"go.uber.org/zap"
+)
+
+const maxSrcsetWidths = 12
+
+// srcsetMimeTypes lists the formats a srcset can be built for, with their <source type>
+var srcsetMimeTypes = map[string]string{
+	"avif": "image/avif",
+	"webp": "image/webp",
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+}
+
+// SrcsetPolicy holds the default breakpoints and formats of a srcset
+type SrcsetPolicy struct {
+	Widths  []int
+	Formats []string // in order of preference, the last one is the fallback
+}
+
+// GetSrcsetQuery represents a query for the responsive delivery URLs of an image.
+// Empty widths and formats fall back to the configured policy.
+type GetSrcsetQuery struct {
+	ImageID string
+	Widths  []int
+	Formats []string
+	Fit     *string
+	Quality *int
+	Expires *time.Time
+	Caller  string // authenticated client; empty for anonymous requests
+}
+
+// SrcsetCandidate is one URL of a srcset with its width descriptor
+type SrcsetCandidate struct {
+	URL   string
+	Width int
+}
+
+// SrcsetSource holds the candidates of one format, a <source> of a <picture>
+type SrcsetSource struct {
+	Format     string
+	Type       string // MIME type for the type attribute
+	Srcset     string // "url 320w, url 640w"
+	Candidates []SrcsetCandidate
+}
+
+// GetSrcsetResult represents the responsive delivery URLs of an image
+type GetSrcsetResult struct {
+	Width       int // intrinsic size with the orientation applied; zero when unknown
+	Height      int
+	Sources     []SrcsetSource
+	FallbackURL string // largest candidate of the last format, for the <img src>
+	ExpiresAt   *time.Time
+}
+
+// GetSrcsetQueryHandler handles GetSrcsetQuery
+type GetSrcsetQueryHandler interface {
+	Handle(ctx context.Context, query GetSrcsetQuery) (*GetSrcsetResult, error)
+}
+
+type getSrcsetHandler struct {
+	repo   image.Repository
+	signer abstraction.ImgproxySigner
+	policy SrcsetPolicy
+}
+
+func NewGetSrcsetHandler(repo image.Repository, signer abstraction.ImgproxySigner, policy SrcsetPolicy) GetSrcsetQueryHandler {
+	return &getSrcsetHandler{
+		repo:   repo,
+		signer: signer,
+		policy: policy,
+	}
+}
+
+func (h *getSrcsetHandler) Handle(ctx context.Context, query GetSrcsetQuery) (*GetSrcsetResult, error) {
+	widths, formats, err := h.resolvePolicy(query)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateProcessing(abstraction.SignerOptions{Fit: query.Fit}); err != nil {
+		return nil, err
+	}
+
+	img, err := h.repo.FindByID(ctx, query.ImageID)
+	if err != nil {
+		if errors.Is(err, persistence.ErrEntityNotFound) {
+			return nil, image.ErrImageNotFound
+		}
+		return nil, fmt.Errorf("failed to get image by id: %w", err)
+	}
+
+	// Images in the trash keep their object but are not delivered
+	if img.IsDeleted() {
+		return nil, image.ErrImageNotFound
+	}
+
+	widths = capWidths(widths, img.DisplayWidth)
+
+	result := &GetSrcsetResult{
+		Width:     img.DisplayWidth,
+		Height:    img.DisplayHeight,
+		Sources:   make([]SrcsetSource, 0, len(formats)),
+		ExpiresAt: query.Expires,
+	}
+	for _, format := range formats {
+		source := SrcsetSource{
+			Format:     format,
+			Type:       srcsetMimeTypes[format],
+			Candidates: make([]SrcsetCandidate, 0, len(widths)),
+		}
+		descriptors := make([]string, 0, len(widths))
+		for _, w := range widths {
+			url := h.signer.BuildURL(img.Key, abstraction.SignerOptions{
+				Width:     &w,
+				Fit:       query.Fit,
+				Quality:   query.Quality,
+				Format:    &format,
+				Expires:   query.Expires,
+				OwnerType: img.OwnerType,
+				Caller:    query.Caller,
+			})
+			source.Candidates = append(source.Candidates, SrcsetCandidate{URL: url, Width: w})
+			descriptors = append(descriptors, url+" "+strconv.Itoa(w)+"w")
+		}
+		source.Srcset = strings.Join(descriptors, ", ")
+		result.Sources = append(result.Sources, source)
+	}
+
+	last := result.Sources[len(result.Sources)-1]
+	result.FallbackURL = last.Candidates[len(last.Candidates)-1].URL
+
+	h.log(ctx).Debug("srcset generated", zap.String("imageID", query.ImageID), zap.Int("urls", len(widths)*len(formats)))
+
+	return result, nil
+}
+
+// resolvePolicy validates the requested widths and formats and fills in the defaults.
+// Widths are sorted ascending and deduplicated.
+func (h *getSrcsetHandler) resolvePolicy(query GetSrcsetQuery) ([]int, []string, error) {
+	widths := query.Widths
+	if len(widths) == 0 {
+		widths = h.policy.Widths
+	}
+	formats := query.Formats
+	if len(formats) == 0 {
+		formats = h.policy.Formats
+	}
+
+	widths = slices.Clone(widths)
+	slices.Sort(widths)
+	widths = slices.Compact(widths)
+	if len(widths) == 0 || len(widths) > maxSrcsetWidths {
+		return nil, nil, fmt.Errorf("%w: between 1 and %d widths are required", image.ErrInvalidDeliveryPolicy, maxSrcsetWidths)
+	}
+	if widths[0] <= 0 {
+		return nil, nil, fmt.Errorf("%w: widths must be positive", image.ErrInvalidDeliveryPolicy)
+	}
+
+	if len(formats) == 0 {
+		return nil, nil, fmt.Errorf("%w: at least one format is required", image.ErrInvalidDeliveryPolicy)
+	}
+	normalized := make([]string, 0, len(formats))
+	for _, f := range formats {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "jpg" {
+			f = "jpeg"
+		}
+		if _, ok := srcsetMimeTypes[f]; !ok {
+			return nil, nil, fmt.Errorf("%w: unsupported format %q", image.ErrInvalidDeliveryPolicy, f)
+		}
+		if !slices.Contains(normalized, f) {
+			normalized = append(normalized, f)
+		}
+	}
+
+	return widths, normalized, nil
+}
+
+// capWidths drops the widths that would upscale the image, keeping the intrinsic width
+// as the largest candidate. Images of unknown size keep all widths.
+func capWidths(widths []int, intrinsic int) []int {
+	if intrinsic <= 0 || widths[len(widths)-1] <= intrinsic {
+		return widths
+	}
+
+	capped := make([]int, 0, len(widths))
+	for _, w := range widths {
+		if w < intrinsic {
+			capped = append(capped, w)
+		}
+	}
+	return append(capped, intrinsic)
+}
+
+func (h *getSrcsetHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "get-srcset-handler"))
+}
@@ -0,0 +1,120 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+func TestGetSrcset(t *testing.T) {
+	policy := SrcsetPolicy{Widths: []int{320, 640, 1280}, Formats: []string{"avif", "jpeg"}}
+	bogus := "stretch"
+
+	tests := []struct {
+		name        string
+		query       GetSrcsetQuery
+		intrinsic   int
+		wantErr     error
+		wantFormats []string
+		wantWidths  []int
+	}{
+		{
+			name:        "configured policy",
+			query:       GetSrcsetQuery{},
+			wantFormats: []string{"avif", "jpeg"},
+			wantWidths:  []int{320, 640, 1280},
+		},
+		{
+			name:        "requested widths are sorted and deduplicated",
+			query:       GetSrcsetQuery{Widths: []int{800, 400, 800}, Formats: []string{" WebP ", "jpg", "jpeg"}},
+			wantFormats: []string{"webp", "jpeg"},
+			wantWidths:  []int{400, 800},
+		},
+		{
+			name:        "widths above the intrinsic width are capped",
+			query:       GetSrcsetQuery{},
+			intrinsic:   900,
+			wantFormats: []string{"avif", "jpeg"},
+			wantWidths:  []int{320, 640, 900},
+		},
+		{
+			name:    "unsupported format",
+			query:   GetSrcsetQuery{Formats: []string{"gif"}},
+			wantErr: image.ErrInvalidDeliveryPolicy,
+		},
+		{
+			name:    "non-positive width",
+			query:   GetSrcsetQuery{Widths: []int{0, 320}},
+			wantErr: image.ErrInvalidDeliveryPolicy,
+		},
+		{
+			name:    "too many widths",
+			query:   GetSrcsetQuery{Widths: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}},
+			wantErr: image.ErrInvalidDeliveryPolicy,
+		},
+		{
+			name:    "unknown fit",
+			query:   GetSrcsetQuery{Fit: &bogus},
+			wantErr: image.ErrInvalidDeliveryPolicy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &singleImageRepo{img: newRenderTestImage(t, "image/jpeg", tt.intrinsic)}
+			handler := NewGetSrcsetHandler(repo, &recordingSigner{}, policy)
+
+			tt.query.ImageID = "img-1"
+			result, err := handler.Handle(context.Background(), tt.query)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+
+			var formats []string
+			for _, source := range result.Sources {
+				formats = append(formats, source.Format)
+				var widths []int
+				for _, c := range source.Candidates {
+					widths = append(widths, c.Width)
+				}
+				if !slices.Equal(widths, tt.wantWidths) {
+					t.Fatalf("%s widths = %v, want %v", source.Format, widths, tt.wantWidths)
+				}
+				if strings.Count(source.Srcset, "w, ")+1 != len(tt.wantWidths) || source.Type != srcsetMimeTypes[source.Format] {
+					t.Fatalf("%s source = %+v", source.Format, source)
+				}
+			}
+			if !slices.Equal(formats, tt.wantFormats) {
+				t.Fatalf("formats = %v, want %v", formats, tt.wantFormats)
+			}
+
+			// The fallback is the largest candidate of the last format
+			last := result.Sources[len(result.Sources)-1]
+			if result.FallbackURL != last.Candidates[len(last.Candidates)-1].URL {
+				t.Fatalf("fallback = %s", result.FallbackURL)
+			}
+		})
+	}
+}
+
+func TestGetSrcsetDoesNotChangeThePolicy(t *testing.T) {
+	policy := SrcsetPolicy{Widths: []int{1280, 320}, Formats: []string{"jpeg"}}
+	handler := NewGetSrcsetHandler(&singleImageRepo{img: newRenderTestImage(t, "image/jpeg", 0)}, &recordingSigner{}, policy)
+
+	if _, err := handler.Handle(context.Background(), GetSrcsetQuery{ImageID: "img-1"}); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if !slices.Equal(policy.Widths, []int{1280, 320}) {
+		t.Fatalf("policy widths changed to %v", policy.Widths)
+	}
+}
@@ -0,0 +1,68 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// ListDuplicateClustersQuery represents a query for groups of images with near-identical perceptual hashes
+type ListDuplicateClustersQuery struct {
+	OwnerType *string
+	Limit     *int
+	Cursor    *string
+}
+
+// ListDuplicateClustersResult represents a page of duplicate clusters
+type ListDuplicateClustersResult struct {
+	Clusters   []image.DuplicateCluster
+	NextCursor *string
+}
+
+// ListDuplicateClustersQueryHandler handles ListDuplicateClustersQuery
+type ListDuplicateClustersQueryHandler interface {
+	Handle(ctx context.Context, query ListDuplicateClustersQuery) (*ListDuplicateClustersResult, error)
+}
+
+type listDuplicateClustersHandler struct {
+	repo        image.Repository
+	maxDistance int
+}
+
+// NewListDuplicateClustersHandler creates the handler; images within maxDistance differing
+// hash bits are clustered together, as for the duplicate policy of uploads
+func NewListDuplicateClustersHandler(repo image.Repository, maxDistance int) ListDuplicateClustersQueryHandler {
+	return &listDuplicateClustersHandler{
+		repo:        repo,
+		maxDistance: maxDistance,
+	}
+}
+
+func (h *listDuplicateClustersHandler) Handle(ctx context.Context, query ListDuplicateClustersQuery) (*ListDuplicateClustersResult, error) {
+	limit := defaultListLimit
+	if query.Limit != nil && *query.Limit > 0 {
+		limit = min(*query.Limit, maxListLimit)
+	}
+
+	// Near-duplicates are found by comparing hashes, which the database cannot group by
+	members, err := h.repo.FindPerceptualHashes(ctx, deref(query.OwnerType))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find perceptual hashes: %w", err)
+	}
+	clusters := image.ClusterNearDuplicates(members, h.maxDistance)
+
+	// Clusters are ordered by their smallest hash, so that hash is the cursor
+	after := deref(query.Cursor)
+	start := sort.Search(len(clusters), func(i int) bool { return clusters[i].PerceptualHash > after })
+	page := clusters[start:]
+
+	result := &ListDuplicateClustersResult{Clusters: page}
+	if len(page) > limit {
+		result.Clusters = page[:limit]
+		next := page[limit-1].PerceptualHash
+		result.NextCursor = &next
+	}
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// ListImagesQuery represents a query to list images with filtering and cursor pagination
+type ListImagesQuery struct {
+	OwnerType     *string
+	OwnerID       *string
+	Status        *string
+	Role          *string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Sort          *string // createdAt | -createdAt
+	Limit         *int
+	Cursor        *string
+}
+
+// ListImagesResult represents a page of images
+type ListImagesResult struct {
+	Images     []*image.Image
+	NextCursor *string
+}
+
+// ListImagesQueryHandler handles ListImagesQuery
+type ListImagesQueryHandler interface {
+	Handle(ctx context.Context, query ListImagesQuery) (*ListImagesResult, error)
+}
+
+type listImagesHandler struct {
+	repo image.Repository
+}
+
+func NewListImagesHandler(repo image.Repository) ListImagesQueryHandler {
+	return &listImagesHandler{repo: repo}
+}
+
+func (h *listImagesHandler) Handle(ctx context.Context, query ListImagesQuery) (*ListImagesResult, error) {
+	criteria := image.SearchCriteria{
+		OwnerType:     deref(query.OwnerType),
+		OwnerID:       deref(query.OwnerID),
+		Status:        image.ImageStatus(deref(query.Status)),
+		Role:          deref(query.Role),
+		CreatedAfter:  query.CreatedAfter,
+		CreatedBefore: query.CreatedBefore,
+		Sort:          image.SortCreatedAtDesc,
+		Limit:         defaultListLimit,
+		Cursor:        deref(query.Cursor),
+	}
+
+	if query.Sort != nil {
+		switch image.SortOrder(*query.Sort) {
+		case image.SortCreatedAtAsc, image.SortCreatedAtDesc:
+			criteria.Sort = image.SortOrder(*query.Sort)
+		default:
+			return nil, fmt.Errorf("%w: unsupported sort %q", image.ErrInvalidSearchCriteria, *query.Sort)
+		}
+	}
+
+	if query.Limit != nil && *query.Limit > 0 {
+		criteria.Limit = min(*query.Limit, maxListLimit)
+	}
+
+	if criteria.OwnerID != "" && criteria.OwnerType == "" {
+		return nil, fmt.Errorf("%w: owner type is required when filtering by owner ID", image.ErrInvalidSearchCriteria)
+	}
+
+	result, err := h.repo.Search(ctx, criteria)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search images: %w", err)
+	}
+
+	var next *string
+	if result.NextCursor != "" {
+		next = &result.NextCursor
+	}
+
+	return &ListImagesResult{
+		Images:     result.Images,
+		NextCursor: next,
+	}, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
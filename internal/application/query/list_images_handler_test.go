@@ -0,0 +1,116 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// recordingSearchRepo returns a fixed page and captures the criteria it was searched with
+type recordingSearchRepo struct {
+	image.Repository
+	criteria *image.SearchCriteria
+	page     image.SearchResult
+}
+
+func (r *recordingSearchRepo) Search(_ context.Context, criteria image.SearchCriteria) (*image.SearchResult, error) {
+	r.criteria = &criteria
+	return &r.page, nil
+}
+
+func TestListImagesCriteria(t *testing.T) {
+	product, ownerID, asc, bogus := image.OwnerTypeProduct, "product-1", "createdAt", "size"
+	limit, huge := 5, 1000
+
+	tests := []struct {
+		name      string
+		query     ListImagesQuery
+		wantErr   error
+		wantSort  image.SortOrder
+		wantLimit int
+	}{
+		{
+			name:      "defaults to newest first",
+			query:     ListImagesQuery{},
+			wantSort:  image.SortCreatedAtDesc,
+			wantLimit: defaultListLimit,
+		},
+		{
+			name:      "ascending with a limit",
+			query:     ListImagesQuery{Sort: &asc, Limit: &limit},
+			wantSort:  image.SortCreatedAtAsc,
+			wantLimit: 5,
+		},
+		{
+			name:      "limit is capped",
+			query:     ListImagesQuery{Limit: &huge},
+			wantSort:  image.SortCreatedAtDesc,
+			wantLimit: maxListLimit,
+		},
+		{
+			name:      "owner filter",
+			query:     ListImagesQuery{OwnerType: &product, OwnerID: &ownerID},
+			wantSort:  image.SortCreatedAtDesc,
+			wantLimit: defaultListLimit,
+		},
+		{
+			name:    "unsupported sort",
+			query:   ListImagesQuery{Sort: &bogus},
+			wantErr: image.ErrInvalidSearchCriteria,
+		},
+		{
+			name:    "owner ID without owner type",
+			query:   ListImagesQuery{OwnerID: &ownerID},
+			wantErr: image.ErrInvalidSearchCriteria,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &recordingSearchRepo{}
+			_, err := NewListImagesHandler(repo).Handle(context.Background(), tt.query)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if repo.criteria != nil {
+					t.Fatal("invalid query reached the repository")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+
+			got := repo.criteria
+			if got.Sort != tt.wantSort || got.Limit != tt.wantLimit {
+				t.Fatalf("criteria sort %q limit %d, want %q %d", got.Sort, got.Limit, tt.wantSort, tt.wantLimit)
+			}
+			if got.OwnerType != deref(tt.query.OwnerType) || got.OwnerID != deref(tt.query.OwnerID) {
+				t.Fatalf("criteria owner %s/%s", got.OwnerType, got.OwnerID)
+			}
+		})
+	}
+}
+
+func TestListImagesNextCursor(t *testing.T) {
+	repo := &recordingSearchRepo{page: image.SearchResult{NextCursor: "next"}}
+	result, err := NewListImagesHandler(repo).Handle(context.Background(), ListImagesQuery{})
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if result.NextCursor == nil || *result.NextCursor != "next" {
+		t.Fatalf("next cursor = %v, want next", result.NextCursor)
+	}
+
+	repo.page.NextCursor = ""
+	result, err = NewListImagesHandler(repo).Handle(context.Background(), ListImagesQuery{})
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if result.NextCursor != nil {
+		t.Fatalf("last page has next cursor %q", *result.NextCursor)
+	}
+}
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/promotion"
+)
+
+// ListPromotionJobsQuery represents a query for the most recent promotion jobs with a status
+type ListPromotionJobsQuery struct {
+	Status string // defaults to failed
+	Limit  *int
+}
+
+// ListPromotionJobsQueryHandler handles ListPromotionJobsQuery
+type ListPromotionJobsQueryHandler interface {
+	Handle(ctx context.Context, query ListPromotionJobsQuery) ([]*promotion.Job, error)
+}
+
+type listPromotionJobsHandler struct {
+	jobs promotion.Repository
+}
+
+func NewListPromotionJobsHandler(jobs promotion.Repository) ListPromotionJobsQueryHandler {
+	return &listPromotionJobsHandler{jobs: jobs}
+}
+
+func (h *listPromotionJobsHandler) Handle(ctx context.Context, query ListPromotionJobsQuery) ([]*promotion.Job, error) {
+	status := promotion.StatusFailed
+	switch promotion.Status(query.Status) {
+	case "":
+	case promotion.StatusPending, promotion.StatusCompleted, promotion.StatusFailed:
+		status = promotion.Status(query.Status)
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", image.ErrInvalidSearchCriteria, query.Status)
+	}
+
+	limit := defaultListLimit
+	if query.Limit != nil && *query.Limit > 0 {
+		limit = min(*query.Limit, maxListLimit)
+	}
+
+	jobs, err := h.jobs.FindByStatus(ctx, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promotion jobs: %w", err)
+	}
+	return jobs, nil
+}
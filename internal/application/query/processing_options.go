@@ -0,0 +1,103 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+const (
+	maxPadding = 1000
+	maxSigma   = 100
+)
+
+var (
+	hexColorPattern    = regexp.MustCompile(`^#?[0-9a-fA-F]{6}$`)
+	cacheBusterPattern = regexp.MustCompile(`^[0-9A-Za-z._-]{1,64}$`)
+)
+
+var fitTypes = map[string]bool{
+	"fit":       true,
+	"fill":      true,
+	"fill-down": true,
+	"force":     true,
+	"auto":      true,
+}
+
+var gravityTypes = map[string]bool{
+	abstraction.GravityCenter:     true,
+	abstraction.GravityNorth:      true,
+	abstraction.GravitySouth:      true,
+	abstraction.GravityEast:       true,
+	abstraction.GravityWest:       true,
+	abstraction.GravityNorthEast:  true,
+	abstraction.GravityNorthWest:  true,
+	abstraction.GravitySouthEast:  true,
+	abstraction.GravitySouthWest:  true,
+	abstraction.GravitySmart:      true,
+	abstraction.GravityFocalPoint: true,
+}
+
+// validateProcessing rejects processing options imgproxy would refuse or that would
+// break the URL path
+func validateProcessing(opts abstraction.SignerOptions) error {
+	if opts.Fit != nil && *opts.Fit != "" && !fitTypes[*opts.Fit] {
+		return fmt.Errorf("%w: unknown fit %q", image.ErrInvalidDeliveryPolicy, *opts.Fit)
+	}
+	if opts.Gravity != nil {
+		if err := validateGravity(*opts.Gravity); err != nil {
+			return err
+		}
+	}
+	if c := opts.Crop; c != nil {
+		if c.Width < 0 || c.Height < 0 {
+			return fmt.Errorf("%w: crop size must not be negative", image.ErrInvalidDeliveryPolicy)
+		}
+		if c.Gravity != nil {
+			if err := validateGravity(*c.Gravity); err != nil {
+				return err
+			}
+		}
+	}
+	if p := opts.Padding; p != nil {
+		for _, v := range []int{p.Top, p.Right, p.Bottom, p.Left} {
+			if v < 0 || v > maxPadding {
+				return fmt.Errorf("%w: padding must be between 0 and %d", image.ErrInvalidDeliveryPolicy, maxPadding)
+			}
+		}
+	}
+	if opts.Background != nil && !hexColorPattern.MatchString(*opts.Background) {
+		return fmt.Errorf("%w: background must be a hex RGB color", image.ErrInvalidDeliveryPolicy)
+	}
+	if opts.Rotate != nil {
+		switch *opts.Rotate {
+		case 0, 90, 180, 270:
+		default:
+			return fmt.Errorf("%w: rotate must be 0, 90, 180 or 270", image.ErrInvalidDeliveryPolicy)
+		}
+	}
+	for name, sigma := range map[string]*float32{"blur": opts.Blur, "sharpen": opts.Sharpen} {
+		if sigma != nil && (*sigma < 0 || *sigma > maxSigma) {
+			return fmt.Errorf("%w: %s must be between 0 and %d", image.ErrInvalidDeliveryPolicy, name, maxSigma)
+		}
+	}
+	if opts.Trim != nil && *opts.Trim < 0 {
+		return fmt.Errorf("%w: trim threshold must not be negative", image.ErrInvalidDeliveryPolicy)
+	}
+	if opts.CacheBuster != nil && !cacheBusterPattern.MatchString(*opts.CacheBuster) {
+		return fmt.Errorf("%w: cache buster must be up to 64 letters, digits, '.', '_' or '-'", image.ErrInvalidDeliveryPolicy)
+	}
+	return nil
+}
+
+func validateGravity(g abstraction.Gravity) error {
+	if !gravityTypes[g.Type] {
+		return fmt.Errorf("%w: unknown gravity %q", image.ErrInvalidDeliveryPolicy, g.Type)
+	}
+	if g.Type == abstraction.GravityFocalPoint && (g.X < 0 || g.X > 1 || g.Y < 0 || g.Y > 1) {
+		return fmt.Errorf("%w: focal point coordinates must be between 0 and 1", image.ErrInvalidDeliveryPolicy)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"go.uber.org/zap"
+)
+
+// VerifyDeliveryURLQuery represents a query for the signing key of a delivery URL
+type VerifyDeliveryURLQuery struct {
+	URL string
+}
+
+// VerifyDeliveryURLQueryHandler handles VerifyDeliveryURLQuery
+type VerifyDeliveryURLQueryHandler interface {
+	Handle(ctx context.Context, query VerifyDeliveryURLQuery) (*abstraction.SignatureCheck, error)
+}
+
+type verifyDeliveryURLHandler struct {
+	signer abstraction.ImgproxySigner
+}
+
+func NewVerifyDeliveryURLHandler(signer abstraction.ImgproxySigner) VerifyDeliveryURLQueryHandler {
+	return &verifyDeliveryURLHandler{signer: signer}
+}
+
+func (h *verifyDeliveryURLHandler) Handle(ctx context.Context, query VerifyDeliveryURLQuery) (*abstraction.SignatureCheck, error) {
+	url := strings.TrimSpace(query.URL)
+	if url == "" {
+		return nil, fmt.Errorf("%w: url is required", image.ErrMalformedDeliveryURL)
+	}
+
+	check, err := h.signer.VerifyURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	h.log(ctx).Debug("delivery URL verified", zap.Bool("valid", check.Valid), zap.String("keyID", check.KeyID))
+
+	return &check, nil
+}
+
+func (h *verifyDeliveryURLHandler) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx).With(zap.String("component", "verify-delivery-url-handler"))
+}
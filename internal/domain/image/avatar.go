@@ -0,0 +1,20 @@
+package image
+
+import "fmt"
+
+// avatarAspectTolerance is how far an avatar may deviate from square, as a fraction of its longer side
+const avatarAspectTolerance = 0.01
+
+// ValidateAvatarDimensions checks that an avatar is (nearly) square
+func ValidateAvatarDimensions(d Dimensions) error {
+	if d.Width <= 0 || d.Height <= 0 {
+		return fmt.Errorf("%w: %dx%d", ErrUnreadableDimensions, d.Width, d.Height)
+	}
+
+	// Rotation does not change squareness, so the stored size is enough
+	longer, shorter := max(d.Width, d.Height), min(d.Width, d.Height)
+	if float64(longer-shorter) > float64(longer)*avatarAspectTolerance {
+		return fmt.Errorf("%w: avatar must be square, got %dx%d", ErrInvalidAspectRatio, d.Width, d.Height)
+	}
+	return nil
+}
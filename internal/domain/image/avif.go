@@ -0,0 +1,181 @@
+package image
+
+import (
+	"encoding/binary"
+)
+
+// isoBox is a box of an ISO-BMFF (HEIF/AVIF) file
+type isoBox struct {
+	typ  string
+	body []byte
+}
+
+// readBoxes splits data into boxes and stops at the first one that is malformed or truncated
+func readBoxes(data []byte) []isoBox {
+	var boxes []isoBox
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[0:4]))
+		typ := string(data[4:8])
+		header := uint64(8)
+		switch size {
+		case 0: // extends to the end of the file
+			size = uint64(len(data))
+		case 1: // 64-bit size follows the type
+			if len(data) < 16 {
+				return boxes
+			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return boxes
+		}
+		boxes = append(boxes, isoBox{typ: typ, body: data[header:size]})
+		data = data[size:]
+	}
+	return boxes
+}
+
+func findBox(boxes []isoBox, typ string) *isoBox {
+	for i := range boxes {
+		if boxes[i].typ == typ {
+			return &boxes[i]
+		}
+	}
+	return nil
+}
+
+// readAVIFDimensions reads the image spatial extents ("ispe") and rotation ("irot") properties
+// associated with the primary item. Files may carry several ispe properties, e.g. for
+// thumbnails or alpha planes, in any order.
+func readAVIFDimensions(head []byte) (Dimensions, error) {
+	meta := findBox(readBoxes(head), "meta")
+	if meta == nil || len(meta.body) < 4 {
+		return Dimensions{}, ErrUnreadableDimensions
+	}
+	children := readBoxes(meta.body[4:]) // full box: version and flags come first
+
+	primary, ok := readPrimaryItemID(findBox(children, "pitm"))
+	iprp := findBox(children, "iprp")
+	if !ok || iprp == nil {
+		return Dimensions{}, ErrUnreadableDimensions
+	}
+	propBoxes := readBoxes(iprp.body)
+	ipco := findBox(propBoxes, "ipco")
+	if ipco == nil {
+		return Dimensions{}, ErrUnreadableDimensions
+	}
+	properties := readBoxes(ipco.body)
+
+	var (
+		dims Dimensions
+		seen bool
+	)
+	for _, box := range propBoxes {
+		if box.typ != "ipma" {
+			continue
+		}
+		for _, index := range readItemProperties(box.body, primary) {
+			if index < 1 || index > len(properties) {
+				continue
+			}
+			prop := properties[index-1]
+			switch {
+			case prop.typ == "ispe" && len(prop.body) >= 12:
+				dims.Width = int(binary.BigEndian.Uint32(prop.body[4:8]))
+				dims.Height = int(binary.BigEndian.Uint32(prop.body[8:12]))
+				seen = true
+			case prop.typ == "irot" && len(prop.body) >= 1:
+				dims.Orientation = irotOrientation(prop.body[0])
+			}
+		}
+	}
+	if !seen {
+		return Dimensions{}, ErrUnreadableDimensions
+	}
+	return dims, nil
+}
+
+// readPrimaryItemID reads the item ID from a primary item ("pitm") box
+func readPrimaryItemID(pitm *isoBox) (uint32, bool) {
+	switch {
+	case pitm == nil || len(pitm.body) < 6:
+		return 0, false
+	case pitm.body[0] == 0:
+		return uint32(binary.BigEndian.Uint16(pitm.body[4:6])), true
+	case len(pitm.body) >= 8:
+		return binary.BigEndian.Uint32(pitm.body[4:8]), true
+	default:
+		return 0, false
+	}
+}
+
+// readItemProperties returns the 1-based property indexes an item property association
+// ("ipma") box gives the item
+func readItemProperties(ipma []byte, itemID uint32) []int {
+	if len(ipma) < 8 {
+		return nil
+	}
+	version, wideIndexes := ipma[0], ipma[3]&1 == 1
+	count := binary.BigEndian.Uint32(ipma[4:8])
+	p := 8
+
+	for range count {
+		var id uint32
+		if version < 1 {
+			if p+2 > len(ipma) {
+				return nil
+			}
+			id = uint32(binary.BigEndian.Uint16(ipma[p : p+2]))
+			p += 2
+		} else {
+			if p+4 > len(ipma) {
+				return nil
+			}
+			id = binary.BigEndian.Uint32(ipma[p : p+4])
+			p += 4
+		}
+		if p >= len(ipma) {
+			return nil
+		}
+		n := int(ipma[p])
+		p++
+
+		var indexes []int
+		for range n {
+			// The top bit marks the property as essential
+			if wideIndexes {
+				if p+2 > len(ipma) {
+					return nil
+				}
+				indexes = append(indexes, int(binary.BigEndian.Uint16(ipma[p:p+2])&0x7fff))
+				p += 2
+			} else {
+				if p >= len(ipma) {
+					return nil
+				}
+				indexes = append(indexes, int(ipma[p]&0x7f))
+				p++
+			}
+		}
+		if id == itemID {
+			return indexes
+		}
+	}
+	return nil
+}
+
+// irotOrientation maps an image rotation, counter-clockwise in units of 90 degrees,
+// to an EXIF orientation
+func irotOrientation(angle byte) int {
+	switch angle & 0x03 {
+	case 1:
+		return 8
+	case 2:
+		return 3
+	case 3:
+		return 6
+	default:
+		return 1
+	}
+}
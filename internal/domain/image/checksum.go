@@ -0,0 +1,38 @@
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// NormalizeChecksum converts a SHA-256 checksum given as base64 or hex into
+// the base64 form used by S3 (x-amz-checksum-sha256)
+func NormalizeChecksum(checksum string) (string, error) {
+	checksum = strings.TrimSpace(checksum)
+
+	if len(checksum) == hex.EncodedLen(sha256.Size) {
+		if raw, err := hex.DecodeString(checksum); err == nil {
+			return base64.StdEncoding.EncodeToString(raw), nil
+		}
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(checksum)
+	if err != nil || len(raw) != sha256.Size {
+		return "", ErrInvalidChecksum
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// IsCompositeChecksum reports whether the checksum is the "<base64>-<parts>" form
+// S3 stores for objects assembled by a multipart upload
+func IsCompositeChecksum(checksum string) bool {
+	i := strings.LastIndexByte(checksum, '-')
+	if i <= 0 {
+		return false
+	}
+	n, err := strconv.Atoi(checksum[i+1:])
+	return err == nil && n > 0
+}
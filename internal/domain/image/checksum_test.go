@@ -0,0 +1,67 @@
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeChecksum(t *testing.T) {
+	sum := sha256.Sum256([]byte("image"))
+	b64 := base64.StdEncoding.EncodeToString(sum[:])
+
+	tests := []struct {
+		name     string
+		checksum string
+		wantErr  bool
+	}{
+		{"base64", b64, false},
+		{"hex", hex.EncodeToString(sum[:]), false},
+		{"upper-case hex", strings.ToUpper(hex.EncodeToString(sum[:])), false},
+		{"surrounding spaces", " " + b64 + "\n", false},
+		{"empty", "", true},
+		{"hex of the wrong length", hex.EncodeToString(sum[:16]), true},
+		{"base64 of the wrong length", base64.StdEncoding.EncodeToString(sum[:16]), true},
+		{"composite multipart checksum", b64 + "-3", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeChecksum(tt.checksum)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidChecksum) {
+					t.Fatalf("err = %v, want %v", err, ErrInvalidChecksum)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeChecksum: %v", err)
+			}
+			if got != b64 {
+				t.Fatalf("NormalizeChecksum = %s, want %s", got, b64)
+			}
+		})
+	}
+}
+
+func TestIsCompositeChecksum(t *testing.T) {
+	tests := []struct {
+		checksum string
+		want     bool
+	}{
+		{"n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=-3", true},
+		{"n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=", false},
+		{"n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=-0", false},
+		{"-3", false},
+		{"abc-x", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsCompositeChecksum(tt.checksum); got != tt.want {
+			t.Errorf("IsCompositeChecksum(%q) = %v, want %v", tt.checksum, got, tt.want)
+		}
+	}
+}
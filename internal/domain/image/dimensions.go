@@ -0,0 +1,255 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	stdimage "image"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// HeaderReadLength is how many leading bytes are read to identify an image and its
+// dimensions. JPEG metadata segments can push the frame header past it; ReadJPEGDimensionsAt
+// then keeps scanning.
+const HeaderReadLength = 256 * 1024
+
+// Dimensions is the pixel size of an image as stored
+type Dimensions struct {
+	Width       int
+	Height      int
+	Orientation int // EXIF orientation 1-8; 0 when unknown, meaning upright
+}
+
+// Display returns the size of the image once its orientation is applied
+func (d Dimensions) Display() (width, height int) {
+	// Orientations 5-8 rotate by 90 degrees one way or the other
+	if d.Orientation >= 5 && d.Orientation <= 8 {
+		return d.Height, d.Width
+	}
+	return d.Width, d.Height
+}
+
+// Pixels returns the number of pixels the image decodes to
+func (d Dimensions) Pixels() int64 {
+	return int64(d.Width) * int64(d.Height)
+}
+
+// ReadDimensions reads the pixel size from the leading bytes of an image of the given MIME type
+func ReadDimensions(head []byte, mime string) (Dimensions, error) {
+	var (
+		cfg         stdimage.Config
+		orientation int
+		err         error
+	)
+
+	switch mime {
+	case "image/jpeg":
+		cfg, err = jpeg.DecodeConfig(bytes.NewReader(head))
+		orientation = readJPEGOrientation(head)
+	case "image/png":
+		cfg, err = png.DecodeConfig(bytes.NewReader(head))
+	case "image/webp":
+		return readWebPDimensions(head)
+	case "image/avif":
+		return readAVIFDimensions(head)
+	default:
+		return Dimensions{}, fmt.Errorf("%w: unsupported mime type %s", ErrUnreadableDimensions, mime)
+	}
+	if err != nil {
+		return Dimensions{}, fmt.Errorf("%w: %w", ErrUnreadableDimensions, err)
+	}
+
+	return Dimensions{Width: cfg.Width, Height: cfg.Height, Orientation: orientation}, nil
+}
+
+// readWebPDimensions parses the first chunk of a RIFF/WEBP container
+func readWebPDimensions(head []byte) (Dimensions, error) {
+	if len(head) < 30 {
+		return Dimensions{}, ErrUnreadableDimensions
+	}
+
+	switch string(head[12:16]) {
+	case "VP8X": // extended: 24-bit canvas size minus one
+		return Dimensions{
+			Width:  int(uint32(head[24])|uint32(head[25])<<8|uint32(head[26])<<16) + 1,
+			Height: int(uint32(head[27])|uint32(head[28])<<8|uint32(head[29])<<16) + 1,
+		}, nil
+	case "VP8 ": // lossy: frame tag, start code 9d 01 2a, then 14-bit sizes
+		if !bytes.Equal(head[23:26], []byte{0x9d, 0x01, 0x2a}) {
+			return Dimensions{}, ErrUnreadableDimensions
+		}
+		return Dimensions{
+			Width:  int(binary.LittleEndian.Uint16(head[26:28]) & 0x3fff),
+			Height: int(binary.LittleEndian.Uint16(head[28:30]) & 0x3fff),
+		}, nil
+	case "VP8L": // lossless: signature 0x2f, then 14-bit sizes minus one
+		if head[20] != 0x2f {
+			return Dimensions{}, ErrUnreadableDimensions
+		}
+		bits := binary.LittleEndian.Uint32(head[21:25])
+		return Dimensions{
+			Width:  int(bits&0x3fff) + 1,
+			Height: int((bits>>14)&0x3fff) + 1,
+		}, nil
+	default:
+		return Dimensions{}, ErrUnreadableDimensions
+	}
+}
+
+// ReadJPEGDimensionsAt walks the segments of a JPEG of the given size until its frame header,
+// reading HeaderReadLength bytes at a time. Metadata segments of up to 64 KB each can push the
+// frame header past any fixed-size header read.
+func ReadJPEGDimensionsAt(r io.ReaderAt, size int64) (Dimensions, error) {
+	var (
+		window    []byte
+		windowOff int64
+	)
+	// at returns n bytes at off, reading a new window when they are not in the current one
+	at := func(off int64, n int) ([]byte, error) {
+		if off < windowOff || off+int64(n) > windowOff+int64(len(window)) {
+			length := max(int64(n), min(HeaderReadLength, size-off))
+			if off+length > size {
+				return nil, fmt.Errorf("%w: truncated JPEG", ErrUnreadableDimensions)
+			}
+			window = make([]byte, length)
+			if _, err := r.ReadAt(window, off); err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			windowOff = off
+		}
+		return window[off-windowOff : off-windowOff+int64(n)], nil
+	}
+
+	soi, err := at(0, 2)
+	if err != nil {
+		return Dimensions{}, err
+	}
+	if soi[0] != 0xff || soi[1] != 0xd8 {
+		return Dimensions{}, fmt.Errorf("%w: not a JPEG", ErrUnreadableDimensions)
+	}
+
+	orientation := 0
+	for off := int64(2); off+4 <= size; {
+		h, err := at(off, 4)
+		if err != nil {
+			return Dimensions{}, err
+		}
+		marker := h[1]
+		switch {
+		case h[0] != 0xff:
+			return Dimensions{}, fmt.Errorf("%w: invalid JPEG marker at %d", ErrUnreadableDimensions, off)
+		case marker == 0xff: // fill byte
+			off++
+			continue
+		case marker == 0x01 || marker >= 0xd0 && marker <= 0xd8: // markers without a segment
+			off += 2
+			continue
+		case marker == 0xda || marker == 0xd9: // start of scan or end of image: no frame header
+			return Dimensions{}, fmt.Errorf("%w: JPEG without frame header", ErrUnreadableDimensions)
+		}
+
+		length := int(binary.BigEndian.Uint16(h[2:4]))
+		if length < 2 {
+			return Dimensions{}, fmt.Errorf("%w: invalid JPEG segment at %d", ErrUnreadableDimensions, off)
+		}
+
+		switch {
+		case isJPEGFrameMarker(marker):
+			// Marker, length and sample precision come before the 16-bit height and width
+			frame, err := at(off, 9)
+			if err != nil {
+				return Dimensions{}, err
+			}
+			height := int(binary.BigEndian.Uint16(frame[5:7]))
+			width := int(binary.BigEndian.Uint16(frame[7:9]))
+			if length < 7 || width == 0 || height == 0 {
+				return Dimensions{}, fmt.Errorf("%w: invalid JPEG frame header", ErrUnreadableDimensions)
+			}
+			return Dimensions{Width: width, Height: height, Orientation: orientation}, nil
+		case marker == 0xe1 && orientation == 0:
+			segment, err := at(off, 2+length)
+			if err != nil {
+				return Dimensions{}, err
+			}
+			if exif := segment[4:]; bytes.HasPrefix(exif, []byte("Exif\x00\x00")) {
+				orientation = readTIFFOrientation(exif[6:])
+			}
+		}
+		off += int64(2 + length)
+	}
+
+	return Dimensions{}, fmt.Errorf("%w: JPEG without frame header", ErrUnreadableDimensions)
+}
+
+// isJPEGFrameMarker reports whether the marker starts a frame (SOF0-SOF15), leaving out
+// DHT, JPG and DAC which share the range
+func isJPEGFrameMarker(marker byte) bool {
+	return marker >= 0xc0 && marker <= 0xcf && marker != 0xc4 && marker != 0xc8 && marker != 0xcc
+}
+
+// readJPEGOrientation reads the orientation tag from the EXIF (APP1) segment
+func readJPEGOrientation(head []byte) int {
+	for i := 2; i+4 <= len(head) && head[i] == 0xff; {
+		marker := head[i+1]
+		if marker == 0xda { // start of scan: no more metadata
+			break
+		}
+		length := int(binary.BigEndian.Uint16(head[i+2 : i+4]))
+		end := i + 2 + length
+		if length < 2 || end > len(head) {
+			break
+		}
+		if segment := head[i+4 : end]; marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return readTIFFOrientation(segment[6:])
+		}
+		i = end
+	}
+	return 0
+}
+
+// readTIFFOrientation finds the orientation tag (0x0112) in the first IFD of a TIFF header
+func readTIFFOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for n := range count {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			if o := int(order.Uint16(tiff[entry+8 : entry+10])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// ValidatePixelCount rejects images that decode to more than maxPixels; zero means unlimited
+func ValidatePixelCount(d Dimensions, maxPixels int64) error {
+	if maxPixels > 0 && d.Pixels() > maxPixels {
+		return fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrTooManyPixels, d.Width, d.Height, maxPixels)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	stdimage "image"
+	"image/jpeg"
+	"testing"
+)
+
+func box(typ string, body ...[]byte) []byte {
+	payload := bytes.Join(body, nil)
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(payload)))
+	return append(append(out, typ...), payload...)
+}
+
+func fullBox(typ string, version byte, flags uint32, body ...[]byte) []byte {
+	header := binary.BigEndian.AppendUint32(nil, uint32(version)<<24|flags)
+	return box(typ, append([][]byte{header}, body...)...)
+}
+
+func u16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func u32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+func ispe(w, h uint32) []byte { return fullBox("ispe", 0, 0, u32(w), u32(h)) }
+
+// testAVIF builds an AVIF header whose first ispe belongs to a thumbnail (item 2),
+// while the primary item 1 uses the second one and a rotation
+func testAVIF() []byte {
+	ipco := box("ipco",
+		ispe(160, 120),         // 1: thumbnail size
+		ispe(4000, 3000),       // 2: primary size
+		box("irot", []byte{1}), // 3: 90 degrees counter-clockwise
+	)
+	ipma := fullBox("ipma", 0, 0, u32(2),
+		u16(2), []byte{1, 0x81},
+		u16(1), []byte{2, 0x82, 0x03},
+	)
+	meta := fullBox("meta", 0, 0,
+		fullBox("hdlr", 0, 0, u32(0), []byte("pict"), make([]byte, 13)),
+		fullBox("pitm", 0, 0, u16(1)),
+		box("iprp", ipco, ipma),
+	)
+	return append(box("ftyp", []byte("avif"), u32(0), []byte("mif1avif")), meta...)
+}
+
+func TestReadAVIFDimensionsUsesPrimaryItem(t *testing.T) {
+	dims, err := ReadDimensions(testAVIF(), "image/avif")
+	if err != nil {
+		t.Fatalf("ReadDimensions: %v", err)
+	}
+	if dims.Width != 4000 || dims.Height != 3000 || dims.Orientation != 8 {
+		t.Errorf("dims = %+v, want 4000x3000 with orientation 8", dims)
+	}
+}
+
+// testJPEGWithMetadata inserts APP segments totalling more than HeaderReadLength after the SOI
+func testJPEGWithMetadata(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, stdimage.NewGray(stdimage.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	encoded := buf.Bytes()
+
+	out := append([]byte{}, encoded[:2]...)
+	segment := make([]byte, 0xfff0)
+	for range HeaderReadLength/len(segment) + 2 {
+		out = append(out, 0xff, 0xe2)
+		out = binary.BigEndian.AppendUint16(out, uint16(len(segment)+2))
+		out = append(out, segment...)
+	}
+	return append(out, encoded[2:]...)
+}
+
+func TestReadJPEGDimensionsPastHeader(t *testing.T) {
+	data := testJPEGWithMetadata(t, 30, 20)
+
+	if _, err := ReadDimensions(data[:HeaderReadLength], "image/jpeg"); err == nil {
+		t.Fatal("frame header unexpectedly within the header read")
+	}
+
+	dims, err := ReadJPEGDimensionsAt(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("ReadJPEGDimensionsAt: %v", err)
+	}
+	if dims.Width != 30 || dims.Height != 20 {
+		t.Errorf("dims = %dx%d, want 30x20", dims.Width, dims.Height)
+	}
+}
+
+func TestReadJPEGDimensionsAtRejectsTruncated(t *testing.T) {
+	data := testJPEGWithMetadata(t, 30, 20)[:HeaderReadLength+100]
+	if _, err := ReadJPEGDimensionsAt(bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Fatal("truncated JPEG accepted")
+	}
+}
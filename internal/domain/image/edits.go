@@ -0,0 +1,121 @@
+package image
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// aspectTolerance is how far a crop may deviate from a requested aspect ratio and still match it
+const aspectTolerance = 0.01
+
+// maxCrops limits the named crops of an image
+const maxCrops = 20
+
+// FocalPoint is the point of interest of the image, in coordinates relative to the
+// displayed image: 0,0 is the top left corner and 1,1 the bottom right one
+type FocalPoint struct {
+	X float64
+	Y float64
+}
+
+// Crop is a named rectangle of the displayed image, in relative coordinates.
+// The name is usually an aspect ratio such as "1:1" or a delivery preset name.
+type Crop struct {
+	Name   string
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// UpdateFocalPoint sets the point that is kept in view when the image is cropped
+func (i *Image) UpdateFocalPoint(fp FocalPoint) error {
+	if !inUnitRange(fp.X) || !inUnitRange(fp.Y) {
+		return fmt.Errorf("%w: focal point must be within the image", ErrInvalidEdit)
+	}
+
+	i.FocalPoint = &fp
+	i.ModifiedAt = time.Now().UTC()
+	i.record(newEvent(EventUpdated))
+	return nil
+}
+
+// UpdateCrops replaces the named crops; an empty set removes them
+func (i *Image) UpdateCrops(crops []Crop) error {
+	if len(crops) > maxCrops {
+		return fmt.Errorf("%w: at most %d crops are allowed", ErrInvalidEdit, maxCrops)
+	}
+
+	names := make(map[string]bool, len(crops))
+	for _, c := range crops {
+		name := strings.TrimSpace(c.Name)
+		if name == "" {
+			return fmt.Errorf("%w: crop name is required", ErrInvalidEdit)
+		}
+		if names[name] {
+			return fmt.Errorf("%w: duplicate crop %q", ErrInvalidEdit, name)
+		}
+		names[name] = true
+
+		if c.Width <= 0 || c.Height <= 0 || !inUnitRange(c.X) || !inUnitRange(c.Y) ||
+			c.X+c.Width > 1+1e-9 || c.Y+c.Height > 1+1e-9 {
+			return fmt.Errorf("%w: crop %q must be a non-empty rectangle within the image", ErrInvalidEdit, name)
+		}
+	}
+
+	i.Crops = nil
+	for _, c := range crops {
+		c.Name = strings.TrimSpace(c.Name)
+		i.Crops = append(i.Crops, c)
+	}
+	i.ModifiedAt = time.Now().UTC()
+	i.record(newEvent(EventUpdated))
+	return nil
+}
+
+// CropNamed returns the crop with the name, or nil
+func (i *Image) CropNamed(name string) *Crop {
+	for idx := range i.Crops {
+		if i.Crops[idx].Name == name {
+			return &i.Crops[idx]
+		}
+	}
+	return nil
+}
+
+// CropForAspect returns the crop named after the aspect ratio ("16:9") or, failing that,
+// the first crop whose pixel proportions match it. Matching by proportions needs the
+// image dimensions.
+func (i *Image) CropForAspect(aspect string) *Crop {
+	if c := i.CropNamed(aspect); c != nil {
+		return c
+	}
+
+	ratio, ok := ParseAspectRatio(aspect)
+	if !ok || !i.HasDimensions() {
+		return nil
+	}
+	for idx, c := range i.Crops {
+		w := c.Width * float64(i.DisplayWidth)
+		h := c.Height * float64(i.DisplayHeight)
+		if math.Abs(w/h-ratio)/ratio <= aspectTolerance {
+			return &i.Crops[idx]
+		}
+	}
+	return nil
+}
+
+// ParseAspectRatio parses "W:H" into W/H
+func ParseAspectRatio(aspect string) (float64, bool) {
+	var w, h float64
+	if _, err := fmt.Sscanf(aspect, "%g:%g", &w, &h); err != nil || w <= 0 || h <= 0 {
+		return 0, false
+	}
+	return w / h, true
+}
+
+func inUnitRange(v float64) bool {
+	return v >= 0 && v <= 1
+}
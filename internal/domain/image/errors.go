@@ -3,11 +3,33 @@ package image
 import "errors"
 
 var (
-	ErrImageNotFound       = errors.New("image not found")
-	ErrInvalidOwnerType    = errors.New("invalid owner type")
-	ErrInvalidImageKey     = errors.New("invalid image key")
-	ErrImageTooLarge       = errors.New("image too large")
-	ErrUnsupportedMimeType = errors.New("unsupported mime type")
-	ErrImageAlreadyDeleted = errors.New("image already deleted")
-	ErrCannotPromoteDraft  = errors.New("only draft images can be promoted")
+	ErrImageNotFound         = errors.New("image not found")
+	ErrInvalidOwnerType      = errors.New("invalid owner type")
+	ErrInvalidImageKey       = errors.New("invalid image key")
+	ErrImageTooLarge         = errors.New("image too large")
+	ErrUnsupportedMimeType   = errors.New("unsupported mime type")
+	ErrImageAlreadyDeleted   = errors.New("image already deleted")
+	ErrImageNotDeleted       = errors.New("image is not deleted")
+	ErrCannotPromoteDraft    = errors.New("only draft images can be promoted")
+	ErrInvalidCursor         = errors.New("invalid cursor")
+	ErrInvalidSearchCriteria = errors.New("invalid search criteria")
+	ErrVersionConflict       = errors.New("image version conflict")
+	ErrPreconditionFailed    = errors.New("image precondition failed")
+	ErrImageRejected         = errors.New("image rejected")
+	ErrContentTypeMismatch   = errors.New("content does not match declared type")
+	ErrChecksumMismatch      = errors.New("checksum mismatch")
+	ErrInvalidChecksum       = errors.New("invalid checksum")
+	ErrInvalidUploadParts    = errors.New("invalid upload parts")
+	ErrInvalidUploadSize     = errors.New("invalid upload size")
+	ErrUnreadableDimensions  = errors.New("cannot read image dimensions")
+	ErrInvalidAspectRatio    = errors.New("invalid aspect ratio")
+	ErrTooManyPixels         = errors.New("image has too many pixels")
+	ErrDuplicateImage        = errors.New("duplicate image")
+	ErrImageAlreadyExists    = errors.New("image already exists")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key reused for a different request")
+	ErrUnknownPreset         = errors.New("unknown delivery preset")
+	ErrInvalidDeliveryPolicy = errors.New("invalid delivery policy")
+	ErrInvalidEdit           = errors.New("invalid image edit")
+	ErrInvalidRole           = errors.New("invalid image role")
+	ErrMalformedDeliveryURL  = errors.New("malformed delivery URL")
 )
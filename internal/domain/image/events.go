@@ -0,0 +1,66 @@
+package image
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType names a change to an image that other services are told about
+type EventType string
+
+const (
+	EventUploaded EventType = "ImageUploaded"
+	EventPromoted EventType = "ImagePromoted"
+	EventReady    EventType = "ImageReady"
+	EventUpdated  EventType = "ImageUpdated"
+	EventDeleted  EventType = "ImageDeleted"
+)
+
+// Event is a change recorded by the aggregate. It is published together with the
+// state of the image it is persisted with.
+type Event struct {
+	ID         string
+	Type       EventType
+	OccurredAt time.Time
+	DraftID    string // EventPromoted: the draft the image was promoted from
+	Hard       bool   // EventDeleted: the record and object are gone for good
+}
+
+// NewDeletedEvent creates the event of a hard delete, which happens outside the aggregate
+func NewDeletedEvent(hard bool) Event {
+	return newEvent(EventDeleted, func(e *Event) { e.Hard = hard })
+}
+
+func newEvent(t EventType, opts ...func(*Event)) Event {
+	e := Event{
+		ID:         uuid.New().String(),
+		Type:       t,
+		OccurredAt: time.Now().UTC(),
+	}
+	for _, opt := range opts {
+		opt(&e)
+	}
+	return e
+}
+
+// record adds an event unless one of the same type is already pending,
+// so that several edits saved together are announced once
+func (i *Image) record(e Event) {
+	for _, pending := range i.events {
+		if pending.Type == e.Type {
+			return
+		}
+	}
+	i.events = append(i.events, e)
+}
+
+// PendingEvents returns the events recorded since the image was last persisted
+func (i *Image) PendingEvents() []Event {
+	return i.events
+}
+
+// ClearEvents drops the pending events once they are persisted
+func (i *Image) ClearEvents() {
+	i.events = nil
+}
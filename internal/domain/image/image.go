@@ -9,18 +9,36 @@ import (
 
 // Image - domain aggregate root
 type Image struct {
-	ID         string
-	Version    int
-	Alt        string
-	OwnerType  string
-	OwnerID    string
-	Role       string
-	Key        string
-	Mime       string
-	Size       int64
-	Status     ImageStatus
-	CreatedAt  time.Time
-	ModifiedAt time.Time
+	ID             string
+	Version        int
+	Alt            string
+	OwnerType      string
+	OwnerID        string
+	Role           string
+	Position       int // order within the owner's gallery, as set by the owner
+	Key            string
+	Mime           string
+	DetectedMime   string // MIME type sniffed from the stored content
+	Size           int64
+	Checksum       string // base64-encoded SHA-256 of the stored object
+	Width          int    // pixel size as stored; zero until read
+	Height         int
+	DisplayWidth   int // pixel size with the EXIF orientation applied
+	DisplayHeight  int
+	PerceptualHash string // 64-bit dHash as hex; empty until computed
+	DuplicateOf    string // ID of an earlier near-identical image of the same owner
+	FocalPoint     *FocalPoint
+	Crops          []Crop // named crops, applied on delivery
+	Status         ImageStatus
+	FailureReason  string // why processing failed; empty unless Status is StatusFailed
+	// ProcessingAttempts counts the processing runs that failed transiently since the image was queued
+	ProcessingAttempts int
+	DeletedAt          *time.Time
+	RestoreStatus      ImageStatus // status to return to on restore; empty unless deleted
+	CreatedAt          time.Time
+	ModifiedAt         time.Time
+
+	events []Event // changes not yet persisted
 }
 
 type ImageStatus string
@@ -29,9 +47,17 @@ const (
 	StatusUploaded   ImageStatus = "uploaded"
 	StatusProcessing ImageStatus = "processing"
 	StatusReady      ImageStatus = "ready"
+	StatusFailed     ImageStatus = "failed"
 	StatusDeleted    ImageStatus = "deleted"
 )
 
+// Owner types
+const (
+	OwnerTypeProductDraft = "productDraft"
+	OwnerTypeProduct      = "product"
+	OwnerTypeUser         = "user" // avatars
+)
+
 // NewImage creates a new image with validation
 func NewImage(alt, ownerType, ownerID, role, key, mime string, size int64) (*Image, error) {
 	if err := validateImageData(ownerType, ownerID, key, mime, size); err != nil {
@@ -39,7 +65,7 @@ func NewImage(alt, ownerType, ownerID, role, key, mime string, size int64) (*Ima
 	}
 
 	now := time.Now().UTC()
-	return &Image{
+	img := &Image{
 		ID:         uuid.New().String(),
 		Version:    1,
 		Alt:        alt,
@@ -52,7 +78,9 @@ func NewImage(alt, ownerType, ownerID, role, key, mime string, size int64) (*Ima
 		Status:     StatusUploaded,
 		CreatedAt:  now,
 		ModifiedAt: now,
-	}, nil
+	}
+	img.record(newEvent(EventUploaded))
+	return img, nil
 }
 
 // NewImageWithID creates an image with a specific ID (for idempotency)
@@ -62,7 +90,7 @@ func NewImageWithID(id, alt, ownerType, ownerID, role, key, mime string, size in
 	}
 
 	now := time.Now().UTC()
-	return &Image{
+	img := &Image{
 		ID:         id,
 		Version:    1,
 		Alt:        alt,
@@ -75,31 +103,37 @@ func NewImageWithID(id, alt, ownerType, ownerID, role, key, mime string, size in
 		Status:     StatusUploaded,
 		CreatedAt:  now,
 		ModifiedAt: now,
-	}, nil
+	}
+	img.record(newEvent(EventUploaded))
+	return img, nil
 }
 
-// Reconstruct rebuilds an image from persistence (no validation)
-func Reconstruct(id string, version int, alt, ownerType, ownerID, role, key, mime string, size int64, status ImageStatus, createdAt, modifiedAt time.Time) *Image {
-	return &Image{
-		ID:         id,
-		Version:    version,
-		Alt:        alt,
-		OwnerType:  ownerType,
-		OwnerID:    ownerID,
-		Role:       role,
-		Key:        key,
-		Mime:       mime,
-		Size:       size,
-		Status:     status,
-		CreatedAt:  createdAt,
-		ModifiedAt: modifiedAt,
-	}
+// Reconstruct rebuilds an image from its persisted state (no validation).
+// Changes recorded on the state are not carried over.
+func Reconstruct(state Image) *Image {
+	state.events = nil
+	return &state
 }
 
 // UpdateAlt updates the image alt text
 func (i *Image) UpdateAlt(alt string) {
 	i.Alt = alt
 	i.ModifiedAt = time.Now().UTC()
+	i.record(newEvent(EventUpdated))
+}
+
+// UpdateRole updates the image role
+func (i *Image) UpdateRole(role string) {
+	i.Role = role
+	i.ModifiedAt = time.Now().UTC()
+	i.record(newEvent(EventUpdated))
+}
+
+// UpdatePosition updates the image position in the owner's gallery
+func (i *Image) UpdatePosition(position int) {
+	i.Position = position
+	i.ModifiedAt = time.Now().UTC()
+	i.record(newEvent(EventUpdated))
 }
 
 // PromoteToProduct promotes the image from draft to product
@@ -108,30 +142,119 @@ func (i *Image) PromoteToProduct(productID, newKey string) error {
 		return errors.New("only draft images can be promoted")
 	}
 
+	draftID := i.OwnerID
 	i.OwnerType = "product"
 	i.OwnerID = productID
 	i.Key = newKey
 	i.Status = StatusProcessing
+	i.ProcessingAttempts = 0
 	i.ModifiedAt = time.Now().UTC()
+	i.record(newEvent(EventPromoted, func(e *Event) { e.DraftID = draftID }))
 	return nil
 }
 
 // MarkAsReady marks the image as ready to use
 func (i *Image) MarkAsReady() {
 	i.Status = StatusReady
+	i.FailureReason = ""
 	i.ModifiedAt = time.Now().UTC()
+	i.record(newEvent(EventReady))
 }
 
-// MarkAsProcessing marks the image as processing
+// MarkAsProcessing marks the image as waiting for processing
 func (i *Image) MarkAsProcessing() {
 	i.Status = StatusProcessing
+	i.FailureReason = ""
+	i.ProcessingAttempts = 0
+	i.ModifiedAt = time.Now().UTC()
+	i.record(newEvent(EventUpdated))
+}
+
+// RecordFailedProcessingAttempt counts a processing run that failed transiently and
+// returns the number of such runs. The image stays in processing.
+func (i *Image) RecordFailedProcessingAttempt() int {
+	i.ProcessingAttempts++
+	i.ModifiedAt = time.Now().UTC()
+	return i.ProcessingAttempts
+}
+
+// MarkAsFailed marks the image as permanently failed processing
+func (i *Image) MarkAsFailed(reason string) {
+	i.Status = StatusFailed
+	i.FailureReason = reason
 	i.ModifiedAt = time.Now().UTC()
+	i.record(newEvent(EventUpdated))
 }
 
-// MarkAsDeleted soft deletes the image
+// RecordDetectedMime stores the MIME type sniffed from the stored content
+func (i *Image) RecordDetectedMime(mime string) {
+	i.DetectedMime = mime
+	i.ModifiedAt = time.Now().UTC()
+}
+
+// RecordChecksum stores the verified SHA-256 checksum of the stored object
+func (i *Image) RecordChecksum(checksum string) {
+	i.Checksum = checksum
+	i.ModifiedAt = time.Now().UTC()
+}
+
+// RecordSize stores the object size observed in storage
+func (i *Image) RecordSize(size int64) {
+	i.Size = size
+	i.ModifiedAt = time.Now().UTC()
+}
+
+// RecordDimensions stores the pixel size read from the image header
+func (i *Image) RecordDimensions(d Dimensions) {
+	i.Width, i.Height = d.Width, d.Height
+	i.DisplayWidth, i.DisplayHeight = d.Display()
+	i.ModifiedAt = time.Now().UTC()
+}
+
+// HasDimensions reports whether the pixel size is known
+func (i *Image) HasDimensions() bool {
+	return i.Width > 0 && i.Height > 0
+}
+
+// RecordPerceptualHash stores the perceptual hash of the image content
+func (i *Image) RecordPerceptualHash(hash string) {
+	i.PerceptualHash = hash
+	i.ModifiedAt = time.Now().UTC()
+}
+
+// FlagAsDuplicateOf marks the image as a near-duplicate of another image
+func (i *Image) FlagAsDuplicateOf(imageID string) {
+	i.DuplicateOf = imageID
+	i.ModifiedAt = time.Now().UTC()
+}
+
+// MarkAsDeleted soft deletes the image; the stored object is kept so that it can be restored
 func (i *Image) MarkAsDeleted() {
+	if i.IsDeleted() {
+		return
+	}
+	now := time.Now().UTC()
+	i.RestoreStatus = i.Status
 	i.Status = StatusDeleted
+	i.DeletedAt = &now
+	i.ModifiedAt = now
+	i.record(NewDeletedEvent(false))
+}
+
+// Restore brings a soft deleted image back in the status it was deleted in
+func (i *Image) Restore() error {
+	if !i.IsDeleted() {
+		return ErrImageNotDeleted
+	}
+	i.Status = i.RestoreStatus
+	if i.Status == "" {
+		i.Status = StatusUploaded
+	}
+	i.RestoreStatus = ""
+	i.DeletedAt = nil
 	i.ModifiedAt = time.Now().UTC()
+	i.record(newEvent(EventUpdated))
+	return nil
 }
 
 // IncrementVersion increments version for optimistic locking
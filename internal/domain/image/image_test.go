@@ -0,0 +1,34 @@
+package image
+
+import "testing"
+
+func TestProcessingTransitionsRecordEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		transition func(*Image)
+		wantStatus ImageStatus
+	}{
+		{"processing", func(i *Image) { i.MarkAsProcessing() }, StatusProcessing},
+		{"failed", func(i *Image) { i.MarkAsFailed("corrupt") }, StatusFailed},
+		{"ready", func(i *Image) { i.MarkAsReady() }, StatusReady},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := NewImage("", OwnerTypeProduct, "p-1", "gallery", "products/p-1/a.jpg", "image/jpeg", 10)
+			if err != nil {
+				t.Fatalf("NewImage: %v", err)
+			}
+			img.ClearEvents()
+
+			tt.transition(img)
+
+			if img.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", img.Status, tt.wantStatus)
+			}
+			if len(img.PendingEvents()) != 1 {
+				t.Errorf("pending events = %v, want one", img.PendingEvents())
+			}
+		})
+	}
+}
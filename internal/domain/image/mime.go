@@ -0,0 +1,66 @@
+package image
+
+import (
+	"bytes"
+	"path"
+	"strings"
+)
+
+var extensionByMime = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/avif": ".avif",
+}
+
+// ExtensionForMime returns the key extension for a supported MIME type, or "" if unsupported
+func ExtensionForMime(mime string) string {
+	return extensionByMime[strings.ToLower(mime)]
+}
+
+// MimeMatchesKey reports whether the key extension is the one issued for the MIME type
+func MimeMatchesKey(mime, key string) bool {
+	ext := ExtensionForMime(mime)
+	return ext != "" && strings.EqualFold(path.Ext(key), ext)
+}
+
+// DetectMime identifies a supported image format from its leading bytes.
+// It returns "" when the content is not one of the supported formats.
+func DetectMime(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg"
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case len(head) >= 12 && bytes.Equal(head[0:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WEBP")):
+		return "image/webp"
+	case isAVIF(head):
+		return "image/avif"
+	default:
+		return ""
+	}
+}
+
+// isAVIF checks the ISO-BMFF "ftyp" box for an AVIF major or compatible brand
+func isAVIF(head []byte) bool {
+	if len(head) < 16 || !bytes.Equal(head[4:8], []byte("ftyp")) {
+		return false
+	}
+
+	boxSize := int(head[0])<<24 | int(head[1])<<16 | int(head[2])<<8 | int(head[3])
+	if boxSize < 16 || boxSize > len(head) {
+		boxSize = len(head)
+	}
+
+	// major brand at 8..12, minor version at 12..16, compatible brands follow
+	for off := 8; off+4 <= boxSize; off += 4 {
+		if off == 12 {
+			continue
+		}
+		switch string(head[off : off+4]) {
+		case "avif", "avis":
+			return true
+		}
+	}
+	return false
+}
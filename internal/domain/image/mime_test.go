@@ -0,0 +1,51 @@
+package image
+
+import "testing"
+
+func TestDetectMime(t *testing.T) {
+	tests := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{"jpeg", []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10}, "image/jpeg"},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"), "image/png"},
+		{"webp", []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), "image/webp"},
+		{"avif major brand", []byte("\x00\x00\x00\x1cftypavif\x00\x00\x00\x00mif1miaf"), "image/avif"},
+		{"avif compatible brand", []byte("\x00\x00\x00\x1cftypmif1\x00\x00\x00\x00miafavif"), "image/avif"},
+		{"avif brand only as minor version", []byte("\x00\x00\x00\x18ftypmif1avifmiaf"), ""},
+		{"heic", []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1"), ""},
+		{"riff that is not webp", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), ""},
+		{"gif", []byte("GIF89a"), ""},
+		{"svg", []byte("<svg xmlns="), ""},
+		{"truncated jpeg", []byte{0xFF, 0xD8}, ""},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectMime(tt.head); got != tt.want {
+				t.Fatalf("DetectMime = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMimeMatchesKey(t *testing.T) {
+	tests := []struct {
+		mime, key string
+		want      bool
+	}{
+		{"image/jpeg", "products/p-1/a.jpg", true},
+		{"IMAGE/PNG", "products/p-1/a.PNG", true},
+		{"image/jpeg", "products/p-1/a.png", false},
+		{"image/gif", "products/p-1/a.gif", false},
+		{"image/webp", "products/p-1/a", false},
+	}
+
+	for _, tt := range tests {
+		if got := MimeMatchesKey(tt.mime, tt.key); got != tt.want {
+			t.Errorf("MimeMatchesKey(%q, %q) = %v, want %v", tt.mime, tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package image
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	stdimage "image"
+	"image/color"
+	"math/bits"
+	"sort"
+)
+
+// Size of the grayscale rendition a difference hash is computed from
+const (
+	DHashWidth  = 9
+	DHashHeight = 8
+)
+
+// DHash computes a 64-bit difference hash as 16 hex characters. Each bit tells whether
+// a pixel is brighter than its right neighbour in a 9x8 rendition of the image; sources
+// of another size are sampled with nearest neighbour.
+func DHash(src stdimage.Image) string {
+	b := src.Bounds()
+	luma := func(x, y int) uint8 {
+		px := b.Min.X + x*b.Dx()/DHashWidth
+		py := b.Min.Y + y*b.Dy()/DHashHeight
+		return color.GrayModel.Convert(src.At(px, py)).(color.Gray).Y
+	}
+
+	var hash uint64
+	for y := range DHashHeight {
+		for x := range DHashWidth - 1 {
+			hash <<= 1
+			if luma(x, y) > luma(x+1, y) {
+				hash |= 1
+			}
+		}
+	}
+
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], hash)
+	return hex.EncodeToString(buf[:])
+}
+
+// HashDistance returns the number of differing bits between two perceptual hashes
+func HashDistance(a, b string) (int, error) {
+	x, err := parseHash(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := parseHash(b)
+	if err != nil {
+		return 0, err
+	}
+	return bits.OnesCount64(x ^ y), nil
+}
+
+func parseHash(s string) (uint64, error) {
+	raw, err := hex.DecodeString(s)
+	if err != nil || len(raw) != 8 {
+		return 0, fmt.Errorf("invalid perceptual hash %q", s)
+	}
+	return binary.BigEndian.Uint64(raw), nil
+}
+
+// FindNearDuplicate returns the first other candidate whose perceptual hash is within
+// maxDistance bits of the image's, or nil when there is none
+func FindNearDuplicate(img *Image, candidates []*Image, maxDistance int) *Image {
+	if img.PerceptualHash == "" {
+		return nil
+	}
+	for _, c := range candidates {
+		if c.ID == img.ID || c.PerceptualHash == "" {
+			continue
+		}
+		if d, err := HashDistance(img.PerceptualHash, c.PerceptualHash); err == nil && d <= maxDistance {
+			return c
+		}
+	}
+	return nil
+}
+
+// ClusterNearDuplicates groups the images whose perceptual hashes are within maxDistance bits
+// of each other, directly or through other members, and returns the groups with more than one
+// member ordered by hash. Images with an invalid hash are ignored.
+func ClusterNearDuplicates(images []DuplicateMember, maxDistance int) []DuplicateCluster {
+	members := make([]DuplicateMember, 0, len(images))
+	hashes := make([]uint64, 0, len(images))
+	for _, m := range images {
+		if h, err := parseHash(m.PerceptualHash); err == nil {
+			members = append(members, m)
+			hashes = append(hashes, h)
+		}
+	}
+
+	parent := make([]int, len(members))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	// Hashes within maxDistance bits agree on at least one of maxDistance+1 blocks of bits,
+	// so only hashes sharing a block are compared
+	blocks := min(max(maxDistance, 0)+1, 64)
+	width := 64 / blocks
+	for b := range blocks {
+		shift := b * width
+		size := width
+		if b == blocks-1 {
+			size = 64 - shift
+		}
+		mask := uint64(1)<<size - 1
+
+		buckets := make(map[uint64][]int)
+		for i, h := range hashes {
+			block := h >> shift & mask
+			for _, j := range buckets[block] {
+				ri, rj := find(i), find(j)
+				if ri != rj && bits.OnesCount64(h^hashes[j]) <= maxDistance {
+					parent[ri] = rj
+				}
+			}
+			buckets[block] = append(buckets[block], i)
+		}
+	}
+
+	groups := make(map[int][]DuplicateMember)
+	for i, m := range members {
+		root := find(i)
+		groups[root] = append(groups[root], m)
+	}
+
+	var clusters []DuplicateCluster
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].PerceptualHash != group[j].PerceptualHash {
+				return group[i].PerceptualHash < group[j].PerceptualHash
+			}
+			return group[i].ImageID < group[j].ImageID
+		})
+		clusters = append(clusters, DuplicateCluster{PerceptualHash: group[0].PerceptualHash, Members: group})
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].PerceptualHash < clusters[j].PerceptualHash })
+
+	return clusters
+}
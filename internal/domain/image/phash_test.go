@@ -0,0 +1,63 @@
+package image
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClusterNearDuplicates(t *testing.T) {
+	member := func(id, hash string) DuplicateMember {
+		return DuplicateMember{ImageID: id, OwnerType: OwnerTypeProduct, OwnerID: "p-1", PerceptualHash: hash}
+	}
+
+	images := []DuplicateMember{
+		member("a", "00000000000000ff"),
+		member("b", "00000000000000fe"), // 1 bit from a
+		member("c", "00000000000000f0"), // 3 bits from b, 4 from a
+		member("d", "ff00000000000000"), // far from everything
+		member("e", "ff00000000000000"), // identical to d
+		member("f", "0f0f0f0f0f0f0f0f"), // alone
+		member("g", "not-a-hash"),
+	}
+
+	tests := []struct {
+		name        string
+		maxDistance int
+		want        [][]string
+	}{
+		{"exact matches only", 0, [][]string{{"d", "e"}}},
+		{"chains through members", 3, [][]string{{"c", "b", "a"}, {"d", "e"}}},
+		{"one bit apart", 1, [][]string{{"b", "a"}, {"d", "e"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters := ClusterNearDuplicates(images, tt.maxDistance)
+
+			var got [][]string
+			for _, c := range clusters {
+				var ids []string
+				for _, m := range c.Members {
+					ids = append(ids, m.ImageID)
+				}
+				if c.PerceptualHash != c.Members[0].PerceptualHash {
+					t.Errorf("cluster hash %s is not its smallest member hash", c.PerceptualHash)
+				}
+				got = append(got, ids)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("clusters = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashDistance(t *testing.T) {
+	d, err := HashDistance("00000000000000ff", "00000000000000f0")
+	if err != nil || d != 4 {
+		t.Errorf("HashDistance = %d, %v; want 4", d, err)
+	}
+	if _, err := HashDistance("00ff", "00000000000000f0"); err == nil {
+		t.Error("short hash accepted")
+	}
+}
@@ -1,15 +1,74 @@
 package image
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Repository interface {
 	Save(ctx context.Context, image *Image) error
 
 	FindByID(ctx context.Context, id string) (*Image, error)
 
+	// FindByKey returns the image stored under the object key, in any status
+	FindByKey(ctx context.Context, key string) (*Image, error)
+
 	FindByOwner(ctx context.Context, ownerType, ownerID string, imageIDs []string) ([]*Image, error)
 
+	Search(ctx context.Context, criteria SearchCriteria) (*SearchResult, error)
+
+	// FindPerceptualHashes returns every live image that has a perceptual hash, of the owner type
+	// or of all owner types when empty
+	FindPerceptualHashes(ctx context.Context, ownerType string) ([]DuplicateMember, error)
+
+	// FindDeletedBefore returns up to limit soft deleted images of the owner type deleted before the given time
+	FindDeletedBefore(ctx context.Context, ownerType string, before time.Time, limit int) ([]*Image, error)
+
+	// FindExistingKeys returns which of the keys belong to an image, in any status
+	FindExistingKeys(ctx context.Context, keys []string) (map[string]bool, error)
+
 	Update(ctx context.Context, image *Image) (*Image, error)
 
 	Delete(ctx context.Context, id string) error
 }
+
+type SortOrder string
+
+const (
+	SortCreatedAtAsc  SortOrder = "createdAt"
+	SortCreatedAtDesc SortOrder = "-createdAt"
+)
+
+// SearchCriteria describes a filtered, keyset-paginated image lookup.
+// Empty fields are not applied as filters.
+type SearchCriteria struct {
+	OwnerType     string
+	OwnerID       string
+	Status        ImageStatus // deleted images are excluded unless requested explicitly
+	Role          string
+	CreatedAfter  *time.Time // inclusive
+	CreatedBefore *time.Time // exclusive
+	Sort          SortOrder
+	Limit         int
+	Cursor        string // opaque cursor returned in SearchResult.NextCursor
+}
+
+// SearchResult contains a page of images and the cursor of the next page
+type SearchResult struct {
+	Images     []*Image
+	NextCursor string // empty when there are no more pages
+}
+
+// DuplicateCluster is a group of live images with near-identical perceptual hashes
+type DuplicateCluster struct {
+	PerceptualHash string // smallest hash of the members, which identifies the cluster
+	Members        []DuplicateMember
+}
+
+// DuplicateMember identifies an image within a duplicate cluster
+type DuplicateMember struct {
+	ImageID        string
+	OwnerType      string
+	OwnerID        string
+	PerceptualHash string
+}
@@ -0,0 +1,12 @@
+package promotion
+
+import "errors"
+
+var (
+	ErrJobNotFound   = errors.New("promotion job not found")
+	ErrJobNotPending = errors.New("promotion job is not pending")
+	ErrJobNotFailed  = errors.New("promotion job is not failed")
+	ErrJobFailed     = errors.New("promotion job failed")
+	ErrJobExists     = errors.New("promotion job already exists")
+	ErrNothingToDo   = errors.New("no images to promote")
+)
@@ -0,0 +1,163 @@
+package promotion
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job - domain aggregate root for moving draft images to a product.
+// Each image goes through its steps in order, and the job is stored after every step,
+// so an interrupted promotion resumes where it stopped.
+type Job struct {
+	ID         string
+	Version    int
+	DraftID    string
+	ProductID  string
+	Items      []Item
+	Status     Status
+	Attempts   int    // number of runs, including the current one
+	LastError  string // why the last run failed; empty unless Status is StatusFailed
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+}
+
+// Item is the promotion of a single image
+type Item struct {
+	ImageID   string
+	SourceKey string
+	TargetKey string
+	Step      Step
+	Error     string // why the last attempt of the next step failed
+}
+
+type Status string
+
+const (
+	StatusPending   Status = "pending" // not started or interrupted; resumed on startup
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed" // waits for a retry
+)
+
+// Step is the last completed step of an item
+type Step string
+
+const (
+	StepPending       Step = "pending"
+	StepCopied        Step = "copied"
+	StepSourceDeleted Step = "sourceDeleted"
+	StepRecordUpdated Step = "recordUpdated"
+)
+
+// NewJob creates a pending job for the given items
+func NewJob(draftID, productID string, items []Item) *Job {
+	return NewJobWithID(uuid.New().String(), draftID, productID, items)
+}
+
+// NewJobWithID creates a pending job with a specific ID (for idempotency)
+func NewJobWithID(id, draftID, productID string, items []Item) *Job {
+	now := time.Now().UTC()
+	planned := make([]Item, len(items))
+	for i, item := range items {
+		item.Step = StepPending
+		item.Error = ""
+		planned[i] = item
+	}
+
+	return &Job{
+		ID:         id,
+		Version:    1,
+		DraftID:    draftID,
+		ProductID:  productID,
+		Items:      planned,
+		Status:     StatusPending,
+		CreatedAt:  now,
+		ModifiedAt: now,
+	}
+}
+
+// Reconstruct rebuilds a job from persistence (no validation)
+func Reconstruct(id string, version int, draftID, productID string, items []Item, status Status, attempts int, lastError string, createdAt, modifiedAt time.Time) *Job {
+	return &Job{
+		ID:         id,
+		Version:    version,
+		DraftID:    draftID,
+		ProductID:  productID,
+		Items:      items,
+		Status:     status,
+		Attempts:   attempts,
+		LastError:  lastError,
+		CreatedAt:  createdAt,
+		ModifiedAt: modifiedAt,
+	}
+}
+
+// Start begins a run of a pending job
+func (j *Job) Start() error {
+	if j.Status != StatusPending {
+		return ErrJobNotPending
+	}
+	j.Attempts++
+	j.ModifiedAt = time.Now().UTC()
+	return nil
+}
+
+// CompleteStep records that the item finished the step
+func (j *Job) CompleteStep(imageID string, step Step) {
+	for i := range j.Items {
+		if j.Items[i].ImageID == imageID {
+			j.Items[i].Step = step
+			j.Items[i].Error = ""
+		}
+	}
+	j.ModifiedAt = time.Now().UTC()
+}
+
+// FailItem records why the next step of the item failed
+func (j *Job) FailItem(imageID, reason string) {
+	for i := range j.Items {
+		if j.Items[i].ImageID == imageID {
+			j.Items[i].Error = reason
+		}
+	}
+	j.ModifiedAt = time.Now().UTC()
+}
+
+// Finish ends the run: the job completes when every item is done and fails otherwise
+func (j *Job) Finish() {
+	j.ModifiedAt = time.Now().UTC()
+	for _, item := range j.Items {
+		if !item.Done() {
+			j.Status = StatusFailed
+			j.LastError = item.Error
+			return
+		}
+	}
+	j.Status = StatusCompleted
+	j.LastError = ""
+}
+
+// Retry returns a failed job to pending so that it runs again
+func (j *Job) Retry() error {
+	if j.Status != StatusFailed {
+		return ErrJobNotFailed
+	}
+	j.Status = StatusPending
+	j.ModifiedAt = time.Now().UTC()
+	return nil
+}
+
+// Item returns the item of the image
+func (j *Job) Item(imageID string) Item {
+	for _, item := range j.Items {
+		if item.ImageID == imageID {
+			return item
+		}
+	}
+	return Item{}
+}
+
+// Done reports whether the item finished all steps
+func (i Item) Done() bool {
+	return i.Step == StepRecordUpdated
+}
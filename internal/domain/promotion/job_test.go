@@ -0,0 +1,101 @@
+package promotion
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTestJob() *Job {
+	return NewJobWithID("job-1", "d-1", "p-1", []Item{
+		{ImageID: "img-1", SourceKey: "product-drafts/d-1/a.jpg", TargetKey: "products/p-1/a.jpg", Step: StepCopied, Error: "stale"},
+		{ImageID: "img-2", SourceKey: "product-drafts/d-1/b.jpg", TargetKey: "products/p-1/b.jpg"},
+	})
+}
+
+func TestNewJobPlansEveryItem(t *testing.T) {
+	j := newTestJob()
+	if j.Status != StatusPending || j.Attempts != 0 || j.Version != 1 {
+		t.Fatalf("new job = %+v", j)
+	}
+	for _, item := range j.Items {
+		if item.Step != StepPending || item.Error != "" {
+			t.Fatalf("item %s starts at %q with error %q", item.ImageID, item.Step, item.Error)
+		}
+	}
+}
+
+func TestJobCompletes(t *testing.T) {
+	j := newTestJob()
+	if err := j.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	for _, id := range []string{"img-1", "img-2"} {
+		for _, step := range []Step{StepCopied, StepSourceDeleted, StepRecordUpdated} {
+			j.CompleteStep(id, step)
+		}
+	}
+	j.Finish()
+
+	if j.Status != StatusCompleted || j.LastError != "" || j.Attempts != 1 {
+		t.Fatalf("job = %+v, want completed after one attempt", j)
+	}
+	if err := j.Start(); !errors.Is(err, ErrJobNotPending) {
+		t.Fatalf("restart of a completed job: err = %v, want %v", err, ErrJobNotPending)
+	}
+	if err := j.Retry(); !errors.Is(err, ErrJobNotFailed) {
+		t.Fatalf("retry of a completed job: err = %v, want %v", err, ErrJobNotFailed)
+	}
+}
+
+func TestJobFailsAndResumes(t *testing.T) {
+	j := newTestJob()
+	if err := j.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	j.CompleteStep("img-1", StepCopied)
+	j.CompleteStep("img-1", StepSourceDeleted)
+	j.CompleteStep("img-1", StepRecordUpdated)
+	j.CompleteStep("img-2", StepCopied)
+	j.FailItem("img-2", "access denied")
+	j.Finish()
+
+	if j.Status != StatusFailed || j.LastError != "access denied" {
+		t.Fatalf("job = %+v, want failed with the item error", j)
+	}
+	if err := j.Start(); !errors.Is(err, ErrJobNotPending) {
+		t.Fatalf("start of a failed job: err = %v, want %v", err, ErrJobNotPending)
+	}
+
+	// A retry resumes from the last completed step of each item
+	if err := j.Retry(); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if err := j.Start(); err != nil {
+		t.Fatalf("start after retry: %v", err)
+	}
+	if j.Attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", j.Attempts)
+	}
+	if item := j.Item("img-1"); !item.Done() {
+		t.Fatalf("finished item was reset: %+v", item)
+	}
+	if item := j.Item("img-2"); item.Step != StepCopied || item.Error != "access denied" {
+		t.Fatalf("failed item = %+v, want copied with its error", item)
+	}
+
+	j.CompleteStep("img-2", StepSourceDeleted)
+	if item := j.Item("img-2"); item.Error != "" {
+		t.Fatalf("completed step kept the error %q", item.Error)
+	}
+	j.CompleteStep("img-2", StepRecordUpdated)
+	j.Finish()
+	if j.Status != StatusCompleted || j.LastError != "" {
+		t.Fatalf("job = %+v, want completed", j)
+	}
+}
+
+func TestJobItemOfUnknownImage(t *testing.T) {
+	if item := newTestJob().Item("other"); item != (Item{}) {
+		t.Fatalf("Item(other) = %+v, want zero", item)
+	}
+}
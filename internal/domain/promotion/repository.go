@@ -0,0 +1,23 @@
+package promotion
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Save(ctx context.Context, job *Job) error
+
+	FindByID(ctx context.Context, id string) (*Job, error)
+
+	// FindByDraft returns the unfinished jobs of the draft, pending or failed
+	FindByDraft(ctx context.Context, draftID string) ([]*Job, error)
+
+	// FindPendingBefore returns up to limit pending jobs last modified before the given time
+	FindPendingBefore(ctx context.Context, before time.Time, limit int) ([]*Job, error)
+
+	// FindByStatus returns up to limit jobs with the status, newest first
+	FindByStatus(ctx context.Context, status Status, limit int) ([]*Job, error)
+
+	Update(ctx context.Context, job *Job) (*Job, error)
+}
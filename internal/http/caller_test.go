@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthenticateCaller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(authenticateCaller(Config{Callers: []CallerCredential{
+		{Name: "storefront", Token: "s3cret"},
+	}}))
+	engine.GET("/whoami", func(c *gin.Context) {
+		c.String(http.StatusOK, callerName(c))
+	})
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantCaller string
+	}{
+		{"anonymous", "", http.StatusOK, ""},
+		{"valid token", "Bearer s3cret", http.StatusOK, "storefront"},
+		{"forwarded user token", "Bearer eyJhbGciOiJSUzI1NiJ9.e30.sig", http.StatusOK, ""},
+		{"caller name instead of token", "Bearer storefront", http.StatusOK, ""},
+		{"other scheme", "Basic s3cret", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != tt.wantCaller {
+				t.Fatalf("caller: got %q, want %q", rec.Body.String(), tt.wantCaller)
+			}
+		})
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(authenticateCaller(Config{Callers: []CallerCredential{
+		{Name: "storefront", Token: "s3cret"},
+		{Name: "ops", Token: "r00t", Admin: true},
+	}}))
+	engine.Group("/admin", requireAdmin).GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{"anonymous", "", http.StatusUnauthorized},
+		{"unknown token", "Bearer guess", http.StatusUnauthorized},
+		{"non-admin caller", "Bearer s3cret", http.StatusForbidden},
+		{"admin caller", "Bearer r00t", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/ping", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+type Config struct {
+	// Callers are the trusted clients, identified by a bearer token. Requests without the
+	// token of a caller are anonymous.
+	Callers []CallerCredential `mapstructure:"callers"`
+}
+
+// CallerCredential authenticates one trusted client
+type CallerCredential struct {
+	Name  string `mapstructure:"name"`  // e.g. "storefront"; referenced by watermark exemptions
+	Token string `mapstructure:"token"` // bearer token, set from the environment
+	Admin bool   `mapstructure:"admin"` // may call the /admin endpoints
+}
+
+func newConfig(v *viper.Viper) (Config, error) {
+	var cfg Config
+	sub := v.Sub("auth")
+	if sub == nil {
+		return cfg, nil
+	}
+	if err := sub.UnmarshalExact(&cfg); err != nil {
+		return cfg, fmt.Errorf("failed to load auth config: %w", err)
+	}
+
+	names := make(map[string]bool, len(cfg.Callers))
+	for i, c := range cfg.Callers {
+		if c.Name == "" {
+			return cfg, fmt.Errorf("caller %d has no name", i)
+		}
+		if names[c.Name] {
+			return cfg, fmt.Errorf("duplicate caller: %s", c.Name)
+		}
+		names[c.Name] = true
+		if c.Token == "" {
+			return cfg, errors.New("caller " + c.Name + " has no token")
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/query"
+	"github.com/gin-gonic/gin"
+)
+
+type duplicateMemberResponse struct {
+	ImageID        string `json:"imageId"`
+	OwnerType      string `json:"ownerType"`
+	OwnerID        string `json:"ownerId"`
+	PerceptualHash string `json:"perceptualHash"`
+}
+
+type duplicateClusterResponse struct {
+	PerceptualHash string                    `json:"perceptualHash"`
+	Images         []duplicateMemberResponse `json:"images"`
+}
+
+type duplicateClusterListResponse struct {
+	Items      []duplicateClusterResponse `json:"items"`
+	NextCursor *string                    `json:"nextCursor,omitempty"`
+}
+
+type duplicatesHandler struct {
+	listHandler query.ListDuplicateClustersQueryHandler
+}
+
+func newDuplicatesHandler(list query.ListDuplicateClustersQueryHandler) *duplicatesHandler {
+	return &duplicatesHandler{listHandler: list}
+}
+
+func (h *duplicatesHandler) register(r gin.IRouter) {
+	r.GET("/images/duplicates", h.list)
+}
+
+func (h *duplicatesHandler) list(c *gin.Context) {
+	q := query.ListDuplicateClustersQuery{}
+	if v, ok := c.GetQuery("ownerType"); ok {
+		q.OwnerType = &v
+	}
+	if v, ok := c.GetQuery("cursor"); ok {
+		q.Cursor = &v
+	}
+	if v, ok := c.GetQuery("limit"); ok {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			writeProblem(c, http.StatusBadRequest, "Invalid request", fmt.Errorf("invalid limit: %w", err))
+			return
+		}
+		q.Limit = &limit
+	}
+
+	result, err := h.listHandler.Handle(c, q)
+	if err != nil {
+		_ = c.Error(err)
+		writeProblem(c, http.StatusInternalServerError, "Internal server error", nil)
+		return
+	}
+
+	items := make([]duplicateClusterResponse, 0, len(result.Clusters))
+	for _, cluster := range result.Clusters {
+		members := make([]duplicateMemberResponse, 0, len(cluster.Members))
+		for _, m := range cluster.Members {
+			members = append(members, duplicateMemberResponse{
+				ImageID:        m.ImageID,
+				OwnerType:      m.OwnerType,
+				OwnerID:        m.OwnerID,
+				PerceptualHash: m.PerceptualHash,
+			})
+		}
+		items = append(items, duplicateClusterResponse{PerceptualHash: cluster.PerceptualHash, Images: members})
+	}
+
+	c.JSON(http.StatusOK, duplicateClusterListResponse{
+		Items:      items,
+		NextCursor: result.NextCursor,
+	})
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Sokol111/ecommerce-image-service-api/api"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/gin-gonic/gin"
+)
+
+// imageETagResponse writes an image together with an ETag derived from its
+// version, so clients can send it back in If-Match on conditional updates.
+type imageETagResponse struct {
+	image api.Image
+	etag  string
+}
+
+func newImageETagResponse(img *image.Image) imageETagResponse {
+	return imageETagResponse{
+		image: *toAPI(img),
+		etag:  formatETag(img.Version),
+	}
+}
+
+func (r imageETagResponse) write(w http.ResponseWriter) error {
+	w.Header().Set("ETag", r.etag)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	return json.NewEncoder(w).Encode(r.image)
+}
+
+func (r imageETagResponse) VisitGetImageResponse(w http.ResponseWriter) error {
+	return r.write(w)
+}
+
+func (r imageETagResponse) VisitUpdateImageResponse(w http.ResponseWriter) error {
+	return r.write(w)
+}
+
+func formatETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseIfMatch converts an If-Match header into the list of accepted versions.
+// It returns nil when the header is absent or "*", and an empty slice when
+// none of the listed entity tags is a version issued by this service.
+func parseIfMatch(header string) []int {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	versions := []int{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		v, err := strconv.Atoi(strings.Trim(tag, `"`))
+		if err != nil {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// requestHeader reads a header from the underlying gin request.
+// Strict handlers receive the *gin.Context as their context.
+func requestHeader(ctx context.Context, name string) string {
+	if c, ok := ctx.(*gin.Context); ok {
+		return c.GetHeader(name)
+	}
+	return ""
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []int
+	}{
+		{"absent", "", nil},
+		{"any", " * ", nil},
+		{"single", `"3"`, []int{3}},
+		{"list with a weak tag", `"3", W/"4"`, []int{3, 4}},
+		{"foreign tags are skipped", `"abc", "5"`, []int{5}},
+		{"only foreign tags", `"abc"`, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseIfMatch(tt.header)
+			if (got == nil) != (tt.want == nil) || !slices.Equal(got, tt.want) {
+				t.Fatalf("parseIfMatch(%q) = %#v, want %#v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatETagRoundTrip(t *testing.T) {
+	etag := formatETag(12)
+	if etag != `"12"` {
+		t.Fatalf("formatETag = %s", etag)
+	}
+	if got := parseIfMatch(etag); !slices.Equal(got, []int{12}) {
+		t.Fatalf("parseIfMatch(%s) = %v", etag, got)
+	}
+}
@@ -8,63 +8,95 @@ import (
 
 	"github.com/Sokol111/ecommerce-commons/pkg/observability"
 	"github.com/Sokol111/ecommerce-image-service-api/api"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
 	"github.com/Sokol111/ecommerce-image-service/internal/application/command"
 	"github.com/Sokol111/ecommerce-image-service/internal/application/query"
 	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/promotion"
 )
 
+// idempotencyKeyHeader lets clients retry ConfirmUpload and PromoteImages safely
+const idempotencyKeyHeader = "Idempotency-Key"
+
 type imageHandler struct {
 	createPresignHandler  command.CreatePresignCommandHandler
 	confirmUploadHandler  command.ConfirmUploadCommandHandler
 	promoteImagesHandler  command.PromoteImagesCommandHandler
+	startPromotionHandler command.StartPromotionCommandHandler
 	deleteImageHandler    command.DeleteImageCommandHandler
+	updateImageHandler    command.UpdateImageCommandHandler
+	processImageHandler   command.ProcessImageCommandHandler
 	getImageByIDHandler   query.GetImageByIDQueryHandler
 	getDeliveryURLHandler query.GetDeliveryURLQueryHandler
+	listImagesHandler     query.ListImagesQueryHandler
 }
 
 func newImageHandler(
 	createPresign command.CreatePresignCommandHandler,
 	confirmUpload command.ConfirmUploadCommandHandler,
 	promoteImages command.PromoteImagesCommandHandler,
+	startPromotion command.StartPromotionCommandHandler,
 	deleteImage command.DeleteImageCommandHandler,
+	updateImage command.UpdateImageCommandHandler,
+	processImage command.ProcessImageCommandHandler,
 	getImageByID query.GetImageByIDQueryHandler,
 	getDeliveryURL query.GetDeliveryURLQueryHandler,
+	listImages query.ListImagesQueryHandler,
 ) api.StrictServerInterface {
 	return &imageHandler{
 		createPresignHandler:  createPresign,
 		confirmUploadHandler:  confirmUpload,
 		promoteImagesHandler:  promoteImages,
+		startPromotionHandler: startPromotion,
 		deleteImageHandler:    deleteImage,
+		updateImageHandler:    updateImage,
+		processImageHandler:   processImage,
 		getImageByIDHandler:   getImageByID,
 		getDeliveryURLHandler: getDeliveryURL,
+		listImagesHandler:     listImages,
 	}
 }
 
 func (h *imageHandler) CreatePresign(ctx context.Context, request api.CreatePresignRequestObject) (api.CreatePresignResponseObject, error) {
 	switch request.Body.OwnerType {
-	case api.ProductDraft, api.Product:
+	case api.ProductDraft, api.Product, api.User:
 		cmd := command.CreatePresignCommand{
 			ContentType: string(request.Body.ContentType),
 			Filename:    request.Body.Filename,
 			OwnerType:   string(request.Body.OwnerType),
 			OwnerID:     request.Body.OwnerId,
 			Size:        int64(request.Body.Size),
+			Checksum:    request.Body.Checksum,
+		}
+		if request.Body.Method != nil {
+			cmd.Method = string(*request.Body.Method)
 		}
 
 		result, err := h.createPresignHandler.Handle(ctx, cmd)
 		if err != nil {
+			switch {
+			case errors.Is(err, image.ErrImageTooLarge):
+				return newProblem(ctx, 413, "Image too large", err.Error()), nil
+			case errors.Is(err, image.ErrInvalidUploadSize):
+				return newProblem(ctx, 400, "Invalid upload size", err.Error()), nil
+			case errors.Is(err, image.ErrInvalidChecksum):
+				return newProblem(ctx, 400, "Invalid checksum", err.Error()), nil
+			}
 			return nil, fmt.Errorf("failed to create presign: %w", err)
 		}
 
-		return api.CreatePresign200JSONResponse{
+		method := api.UploadMethod(result.Method)
+		response := api.CreatePresign200JSONResponse{
 			UploadUrl:       result.UploadURL,
 			Key:             result.Key,
 			ExpiresIn:       result.ExpiresIn,
 			RequiredHeaders: result.RequiredHeaders,
-		}, nil
-
-	case api.User:
-		return nil, fmt.Errorf("unsupported ownerType: %s", request.Body.OwnerType)
+			Method:          &method,
+		}
+		if result.FormFields != nil {
+			response.Fields = &result.FormFields
+		}
+		return response, nil
 
 	default:
 		return nil, fmt.Errorf("unsupported ownerType: %s", request.Body.OwnerType)
@@ -73,7 +105,7 @@ func (h *imageHandler) CreatePresign(ctx context.Context, request api.CreatePres
 
 func (h *imageHandler) ConfirmUpload(ctx context.Context, request api.ConfirmUploadRequestObject) (api.ConfirmUploadResponseObject, error) {
 	switch request.Body.OwnerType {
-	case api.ProductDraft:
+	case api.ProductDraft, api.Product, api.User:
 		cmd := command.ConfirmUploadCommand{
 			Alt:       request.Body.Alt,
 			Key:       request.Body.Key,
@@ -82,32 +114,40 @@ func (h *imageHandler) ConfirmUpload(ctx context.Context, request api.ConfirmUpl
 			OwnerType: string(request.Body.OwnerType),
 			OwnerID:   request.Body.OwnerId,
 			Checksum:  request.Body.Checksum,
+
+			IdempotencyKey: requestHeader(ctx, idempotencyKeyHeader),
 		}
 
 		img, err := h.confirmUploadHandler.Handle(ctx, cmd)
 		if err != nil {
+			switch {
+			case errors.Is(err, image.ErrContentTypeMismatch):
+				return newProblem(ctx, 422, "Uploaded content does not match declared type", err.Error()), nil
+			case errors.Is(err, image.ErrChecksumMismatch):
+				return newProblem(ctx, 422, "Uploaded content does not match checksum", err.Error()), nil
+			case errors.Is(err, image.ErrInvalidChecksum):
+				return newProblem(ctx, 400, "Invalid checksum", err.Error()), nil
+			case errors.Is(err, image.ErrImageTooLarge):
+				return newProblem(ctx, 413, "Image too large", err.Error()), nil
+			case errors.Is(err, image.ErrUnreadableDimensions):
+				return newProblem(ctx, 422, "Cannot read image dimensions", err.Error()), nil
+			case errors.Is(err, image.ErrTooManyPixels):
+				return newProblem(ctx, 422, "Image has too many pixels", err.Error()), nil
+			case errors.Is(err, image.ErrInvalidAspectRatio):
+				return newProblem(ctx, 422, "Invalid avatar", err.Error()), nil
+			case errors.Is(err, image.ErrDuplicateImage):
+				return newProblem(ctx, 409, "Duplicate image", err.Error()), nil
+			case errors.Is(err, image.ErrImageAlreadyDeleted):
+				return newProblem(ctx, 409, "Image already deleted", err.Error()), nil
+			case errors.Is(err, image.ErrImageAlreadyExists):
+				return newProblem(ctx, 409, "Image already exists", err.Error()), nil
+			case errors.Is(err, image.ErrIdempotencyKeyReused):
+				return newProblem(ctx, 422, "Idempotency key reused", err.Error()), nil
+			}
 			return nil, fmt.Errorf("failed to confirm upload: %w", err)
 		}
 
-		return api.ConfirmUpload201JSONResponse{
-			Id:         img.ID,
-			Alt:        img.Alt,
-			OwnerType:  api.OwnerType(img.OwnerType),
-			OwnerId:    img.OwnerID,
-			Role:       api.ImageRole(img.Role),
-			Key:        img.Key,
-			Mime:       img.Mime,
-			Size:       int(img.Size),
-			Status:     api.ImageStatus(img.Status),
-			CreatedAt:  img.CreatedAt,
-			ModifiedAt: img.ModifiedAt,
-		}, nil
-
-	case api.Product:
-		return nil, fmt.Errorf("unsupported ownerType: %s", request.Body.OwnerType)
-
-	case api.User:
-		return nil, fmt.Errorf("unsupported ownerType: %s", request.Body.OwnerType)
+		return api.ConfirmUpload201JSONResponse(*toAPI(img)), nil
 
 	default:
 		return nil, fmt.Errorf("unsupported ownerType: %s", request.Body.OwnerType)
@@ -119,6 +159,20 @@ func (h *imageHandler) PromoteImages(ctx context.Context, request api.PromoteIma
 		DraftID:   request.Body.DraftId,
 		ImageIDs:  request.Body.Images,
 		ProductID: request.Body.ProductId,
+
+		IdempotencyKey: requestHeader(ctx, idempotencyKeyHeader),
+	}
+
+	// Clients that prefer not to wait get the promotion jobs to poll.
+	// A promotion with nothing left to do falls through to replay the promoted images.
+	if preferAsync(ctx) {
+		jobs, err := h.startPromotionHandler.Handle(ctx, cmd)
+		if err != nil && !errors.Is(err, promotion.ErrNothingToDo) {
+			return nil, fmt.Errorf("failed to start promotion: %w", err)
+		}
+		if err == nil {
+			return newPromotionAccepted(jobs), nil
+		}
 	}
 
 	images, err := h.promoteImagesHandler.Handle(ctx, cmd)
@@ -153,6 +207,8 @@ func (h *imageHandler) GetDeliveryUrl(ctx context.Context, request api.GetDelive
 
 	q := query.GetDeliveryURLQuery{
 		ImageID: request.Id,
+		Preset:  request.Params.Preset,
+		Aspect:  request.Params.Aspect,
 		Width:   request.Params.W,
 		Height:  request.Params.H,
 		Fit:     fit,
@@ -160,10 +216,21 @@ func (h *imageHandler) GetDeliveryUrl(ctx context.Context, request api.GetDelive
 		DPR:     request.Params.Dpr,
 		Format:  format,
 		Expires: expires,
+		Caller:  callerName(ctx),
 	}
+	applyProcessingParams(&q, request.Params)
 
 	result, err := h.getDeliveryURLHandler.Handle(ctx, q)
 	if err != nil {
+		if errors.Is(err, image.ErrImageNotFound) {
+			return newProblem(ctx, 404, "Image not found", err.Error()), nil
+		}
+		if errors.Is(err, image.ErrUnknownPreset) {
+			return newProblem(ctx, 400, "Unknown preset", err.Error()), nil
+		}
+		if errors.Is(err, image.ErrInvalidDeliveryPolicy) {
+			return newProblem(ctx, 400, "Invalid processing options", err.Error()), nil
+		}
 		return nil, fmt.Errorf("failed to get delivery URL: %w", err)
 	}
 
@@ -174,6 +241,45 @@ func (h *imageHandler) GetDeliveryUrl(ctx context.Context, request api.GetDelive
 	return response, nil
 }
 
+// applyProcessingParams maps the optional imgproxy processing parameters onto the query
+func applyProcessingParams(q *query.GetDeliveryURLQuery, p api.GetDeliveryUrlParams) {
+	if p.Gravity != nil {
+		q.Gravity = &abstraction.Gravity{Type: string(*p.Gravity)}
+		if p.GravityX != nil {
+			q.Gravity.X = float64(*p.GravityX)
+		}
+		if p.GravityY != nil {
+			q.Gravity.Y = float64(*p.GravityY)
+		}
+	}
+	if p.CropW != nil || p.CropH != nil {
+		q.Crop = &abstraction.Crop{}
+		if p.CropW != nil {
+			q.Crop.Width = float64(*p.CropW)
+		}
+		if p.CropH != nil {
+			q.Crop.Height = float64(*p.CropH)
+		}
+	}
+	if p.Padding != nil {
+		q.Padding = &abstraction.Padding{Top: *p.Padding, Right: *p.Padding, Bottom: *p.Padding, Left: *p.Padding}
+	}
+	if p.FlipH != nil || p.FlipV != nil {
+		q.Flip = &abstraction.Flip{
+			Horizontal: p.FlipH != nil && *p.FlipH,
+			Vertical:   p.FlipV != nil && *p.FlipV,
+		}
+	}
+	q.Background = p.Bg
+	q.Rotate = p.Rotate
+	q.Blur = p.Blur
+	q.Sharpen = p.Sharpen
+	q.Trim = p.Trim
+	q.StripMetadata = p.StripMetadata
+	q.StripColorProfile = p.StripColorProfile
+	q.CacheBuster = p.Cb
+}
+
 func (h *imageHandler) DeleteImage(ctx context.Context, request api.DeleteImageRequestObject) (api.DeleteImageResponseObject, error) {
 	hard := false
 	if request.Params.Hard != nil {
@@ -187,6 +293,9 @@ func (h *imageHandler) DeleteImage(ctx context.Context, request api.DeleteImageR
 
 	err := h.deleteImageHandler.Handle(ctx, cmd)
 	if err != nil {
+		if errors.Is(err, image.ErrImageNotFound) {
+			return newProblem(ctx, 404, "Image not found", err.Error()), nil
+		}
 		return nil, fmt.Errorf("failed to delete image [%v]: %w", request.Id, err)
 	}
 
@@ -222,23 +331,124 @@ func (h *imageHandler) GetImage(ctx context.Context, request api.GetImageRequest
 			}), nil
 	}
 
-	return api.GetImage200JSONResponse(*toAPI(img)), nil
+	return newImageETagResponse(img), nil
 }
 
 func (h *imageHandler) ListImages(ctx context.Context, request api.ListImagesRequestObject) (api.ListImagesResponseObject, error) {
-	panic("unimplemented")
+	q := query.ListImagesQuery{
+		OwnerID:       request.Params.OwnerId,
+		CreatedAfter:  request.Params.CreatedAfter,
+		CreatedBefore: request.Params.CreatedBefore,
+		Limit:         request.Params.Limit,
+		Cursor:        request.Params.Cursor,
+	}
+	if request.Params.OwnerType != nil {
+		t := string(*request.Params.OwnerType)
+		q.OwnerType = &t
+	}
+	if request.Params.Status != nil {
+		s := string(*request.Params.Status)
+		q.Status = &s
+	}
+	if request.Params.Role != nil {
+		r := string(*request.Params.Role)
+		q.Role = &r
+	}
+	if request.Params.Sort != nil {
+		s := string(*request.Params.Sort)
+		q.Sort = &s
+	}
+
+	result, err := h.listImagesHandler.Handle(ctx, q)
+	if err != nil {
+		if errors.Is(err, image.ErrInvalidCursor) || errors.Is(err, image.ErrInvalidSearchCriteria) {
+			return newProblem(ctx, 400, "Invalid list query", err.Error()), nil
+		}
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	items := make([]api.Image, 0, len(result.Images))
+	for _, img := range result.Images {
+		items = append(items, *toAPI(img))
+	}
+
+	return api.ListImages200JSONResponse{
+		Items:      items,
+		NextCursor: result.NextCursor,
+	}, nil
 }
 
 func (h *imageHandler) ProcessImage(ctx context.Context, request api.ProcessImageRequestObject) (api.ProcessImageResponseObject, error) {
-	panic("unimplemented")
+	cmd := command.ProcessImageCommand{
+		ImageID: request.Id,
+	}
+
+	img, err := h.processImageHandler.Handle(ctx, cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, image.ErrImageNotFound):
+			return newProblem(ctx, 404, "Image not found", ""), nil
+		case errors.Is(err, image.ErrImageAlreadyDeleted):
+			return newProblem(ctx, 404, "Image deleted", ""), nil
+		}
+		return nil, fmt.Errorf("failed to process image [%v]: %w", request.Id, err)
+	}
+
+	return api.ProcessImage200JSONResponse(*toAPI(img)), nil
 }
 
 func (h *imageHandler) UpdateImage(ctx context.Context, request api.UpdateImageRequestObject) (api.UpdateImageResponseObject, error) {
-	panic("unimplemented")
+	cmd := command.UpdateImageCommand{
+		ImageID: request.Id,
+		Alt:     request.Body.Alt,
+		Version: request.Body.Version,
+		IfMatch: parseIfMatch(requestHeader(ctx, "If-Match")),
+	}
+	if request.Body.Role != nil {
+		role := string(*request.Body.Role)
+		cmd.Role = &role
+	}
+	if fp := request.Body.FocalPoint; fp != nil {
+		cmd.FocalPoint = &image.FocalPoint{X: float64(fp.X), Y: float64(fp.Y)}
+	}
+	if request.Body.Crops != nil {
+		crops := make([]image.Crop, 0, len(*request.Body.Crops))
+		for _, c := range *request.Body.Crops {
+			crops = append(crops, image.Crop{
+				Name:   c.Name,
+				X:      float64(c.X),
+				Y:      float64(c.Y),
+				Width:  float64(c.Width),
+				Height: float64(c.Height),
+			})
+		}
+		cmd.Crops = &crops
+	}
+
+	img, err := h.updateImageHandler.Handle(ctx, cmd)
+	if err != nil {
+		switch {
+		case errors.Is(err, image.ErrImageNotFound):
+			return newProblem(ctx, 404, "Image not found", ""), nil
+		case errors.Is(err, image.ErrImageAlreadyDeleted):
+			return newProblem(ctx, 404, "Image deleted", ""), nil
+		case errors.Is(err, image.ErrPreconditionFailed):
+			return newProblem(ctx, 412, "Image version does not match If-Match", ""), nil
+		case errors.Is(err, image.ErrVersionConflict):
+			return newProblem(ctx, 409, "Image was modified concurrently", ""), nil
+		case errors.Is(err, image.ErrInvalidEdit):
+			return newProblem(ctx, 422, "Invalid image edit", err.Error()), nil
+		case errors.Is(err, image.ErrInvalidRole):
+			return newProblem(ctx, 422, "Invalid image role", err.Error()), nil
+		}
+		return nil, fmt.Errorf("failed to update image [%v]: %w", request.Id, err)
+	}
+
+	return newImageETagResponse(img), nil
 }
 
 func toAPI(img *image.Image) *api.Image {
-	return &api.Image{
+	out := &api.Image{
 		Id:         img.ID,
 		Alt:        img.Alt,
 		OwnerType:  api.OwnerType(img.OwnerType),
@@ -251,4 +461,31 @@ func toAPI(img *image.Image) *api.Image {
 		CreatedAt:  img.CreatedAt,
 		ModifiedAt: img.ModifiedAt,
 	}
+	if img.HasDimensions() {
+		out.Width, out.Height = &img.Width, &img.Height
+		out.DisplayWidth, out.DisplayHeight = &img.DisplayWidth, &img.DisplayHeight
+	}
+	if img.Position > 0 {
+		out.Position = &img.Position
+	}
+	if img.FocalPoint != nil {
+		out.FocalPoint = &api.FocalPoint{X: float32(img.FocalPoint.X), Y: float32(img.FocalPoint.Y)}
+	}
+	if len(img.Crops) > 0 {
+		crops := make([]api.ImageCrop, 0, len(img.Crops))
+		for _, c := range img.Crops {
+			crops = append(crops, api.ImageCrop{
+				Name:   c.Name,
+				X:      float32(c.X),
+				Y:      float32(c.Y),
+				Width:  float32(c.Width),
+				Height: float32(c.Height),
+			})
+		}
+		out.Crops = &crops
+	}
+	if img.DuplicateOf != "" {
+		out.DuplicateOf = &img.DuplicateOf
+	}
+	return out
 }
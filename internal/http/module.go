@@ -9,7 +9,15 @@ import (
 func NewHttpHandlerModule() fx.Option {
 	return fx.Options(
 		fx.Provide(
+			newConfig,
 			newImageHandler,
+			newMultipartHandler,
+			newDuplicatesHandler,
+			newTrashHandler,
+			newPromotionHandler,
+			newSrcsetHandler,
+			newRenderHandler,
+			newSignatureHandler,
 			func(ssi api.StrictServerInterface) api.ServerInterface {
 				return api.NewStrictHandler(ssi, nil)
 			},
@@ -18,6 +26,32 @@ func NewHttpHandlerModule() fx.Option {
 	)
 }
 
-func registerRoutes(engine *gin.Engine, serverInterface api.ServerInterface) {
+func registerRoutes(
+	engine *gin.Engine,
+	cfg Config,
+	serverInterface api.ServerInterface,
+	multipart *multipartHandler,
+	duplicates *duplicatesHandler,
+	trash *trashHandler,
+	promotions *promotionHandler,
+	srcset *srcsetHandler,
+	render *renderHandler,
+	signatures *signatureHandler,
+) {
+	// Resolve the caller first so that every endpoint sees it
+	engine.Use(authenticateCaller(cfg))
+
 	api.RegisterHandlers(engine, serverInterface)
+
+	// Endpoints served outside the generated API
+	multipart.register(engine)
+	duplicates.register(engine)
+	trash.register(engine)
+	promotions.register(engine)
+	srcset.register(engine)
+	render.register(engine)
+
+	// Operator endpoints
+	admin := engine.Group("/admin", requireAdmin)
+	signatures.register(admin)
 }
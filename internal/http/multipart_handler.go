@@ -0,0 +1,212 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/command"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/gin-gonic/gin"
+)
+
+// Multipart upload endpoints complement CreatePresign for large originals:
+// create -> upload parts -> complete -> ConfirmUpload.
+
+type createMultipartUploadRequest struct {
+	ContentType string `json:"contentType" binding:"required"`
+	Filename    string `json:"filename"`
+	OwnerType   string `json:"ownerType" binding:"required"`
+	OwnerID     string `json:"ownerId" binding:"required"`
+	Size        int64  `json:"size" binding:"required"`
+}
+
+type presignedPartResponse struct {
+	PartNumber int32  `json:"partNumber"`
+	URL        string `json:"url"`
+}
+
+type createMultipartUploadResponse struct {
+	Key             string                  `json:"key"`
+	UploadID        string                  `json:"uploadId"`
+	PartSize        int64                   `json:"partSize"`
+	Parts           []presignedPartResponse `json:"parts"`
+	ExpiresIn       int                     `json:"expiresIn"`
+	RequiredHeaders map[string]string       `json:"requiredHeaders"`
+}
+
+type presignUploadPartsRequest struct {
+	OwnerType   string  `json:"ownerType" binding:"required"`
+	OwnerID     string  `json:"ownerId" binding:"required"`
+	Key         string  `json:"key" binding:"required"`
+	PartNumbers []int32 `json:"partNumbers" binding:"required"`
+}
+
+type presignUploadPartsResponse struct {
+	Parts           []presignedPartResponse `json:"parts"`
+	ExpiresIn       int                     `json:"expiresIn"`
+	RequiredHeaders map[string]string       `json:"requiredHeaders"`
+}
+
+type uploadedPartRequest struct {
+	PartNumber int32  `json:"partNumber" binding:"required"`
+	ETag       string `json:"etag" binding:"required"`
+	Checksum   string `json:"checksum"`
+}
+
+type completeMultipartUploadRequest struct {
+	OwnerType string                `json:"ownerType" binding:"required"`
+	OwnerID   string                `json:"ownerId" binding:"required"`
+	Key       string                `json:"key" binding:"required"`
+	Parts     []uploadedPartRequest `json:"parts" binding:"required"`
+}
+
+type multipartHandler struct {
+	createHandler       command.CreateMultipartUploadCommandHandler
+	presignPartsHandler command.PresignUploadPartsCommandHandler
+	completeHandler     command.CompleteMultipartUploadCommandHandler
+	abortHandler        command.AbortMultipartUploadCommandHandler
+}
+
+func newMultipartHandler(
+	create command.CreateMultipartUploadCommandHandler,
+	presignParts command.PresignUploadPartsCommandHandler,
+	complete command.CompleteMultipartUploadCommandHandler,
+	abort command.AbortMultipartUploadCommandHandler,
+) *multipartHandler {
+	return &multipartHandler{
+		createHandler:       create,
+		presignPartsHandler: presignParts,
+		completeHandler:     complete,
+		abortHandler:        abort,
+	}
+}
+
+func (h *multipartHandler) register(r gin.IRouter) {
+	g := r.Group("/images/multipart-uploads")
+	g.POST("", h.create)
+	g.POST("/:uploadId/parts", h.presignParts)
+	g.POST("/:uploadId/complete", h.complete)
+	g.DELETE("/:uploadId", h.abort)
+}
+
+func (h *multipartHandler) create(c *gin.Context) {
+	var req createMultipartUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeProblem(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	result, err := h.createHandler.Handle(c, command.CreateMultipartUploadCommand{
+		ContentType: req.ContentType,
+		Filename:    req.Filename,
+		OwnerType:   req.OwnerType,
+		OwnerID:     req.OwnerID,
+		Size:        req.Size,
+	})
+	if err != nil {
+		writeMultipartError(c, fmt.Errorf("failed to create multipart upload: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, createMultipartUploadResponse{
+		Key:             result.Key,
+		UploadID:        result.UploadID,
+		PartSize:        result.PartSize,
+		Parts:           toPresignedPartsResponse(result.Parts),
+		ExpiresIn:       result.ExpiresIn,
+		RequiredHeaders: result.RequiredHeaders,
+	})
+}
+
+func (h *multipartHandler) presignParts(c *gin.Context) {
+	var req presignUploadPartsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeProblem(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	result, err := h.presignPartsHandler.Handle(c, command.PresignUploadPartsCommand{
+		OwnerType:   req.OwnerType,
+		OwnerID:     req.OwnerID,
+		Key:         req.Key,
+		UploadID:    c.Param("uploadId"),
+		PartNumbers: req.PartNumbers,
+	})
+	if err != nil {
+		writeMultipartError(c, fmt.Errorf("failed to presign upload parts: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, presignUploadPartsResponse{
+		Parts:           toPresignedPartsResponse(result.Parts),
+		ExpiresIn:       result.ExpiresIn,
+		RequiredHeaders: result.RequiredHeaders,
+	})
+}
+
+func (h *multipartHandler) complete(c *gin.Context) {
+	var req completeMultipartUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeProblem(c, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	parts := make([]command.UploadedPart, 0, len(req.Parts))
+	for _, p := range req.Parts {
+		parts = append(parts, command.UploadedPart{
+			PartNumber: p.PartNumber,
+			ETag:       p.ETag,
+			Checksum:   p.Checksum,
+		})
+	}
+
+	err := h.completeHandler.Handle(c, command.CompleteMultipartUploadCommand{
+		OwnerType: req.OwnerType,
+		OwnerID:   req.OwnerID,
+		Key:       req.Key,
+		UploadID:  c.Param("uploadId"),
+		Parts:     parts,
+	})
+	if err != nil {
+		writeMultipartError(c, fmt.Errorf("failed to complete multipart upload: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *multipartHandler) abort(c *gin.Context) {
+	err := h.abortHandler.Handle(c, command.AbortMultipartUploadCommand{
+		OwnerType: c.Query("ownerType"),
+		OwnerID:   c.Query("ownerId"),
+		Key:       c.Query("key"),
+		UploadID:  c.Param("uploadId"),
+	})
+	if err != nil {
+		writeMultipartError(c, fmt.Errorf("failed to abort multipart upload: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func writeMultipartError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, image.ErrImageTooLarge):
+		writeProblem(c, http.StatusRequestEntityTooLarge, "Image too large", err)
+	case errors.Is(err, image.ErrInvalidUploadParts), errors.Is(err, image.ErrInvalidImageKey):
+		writeProblem(c, http.StatusBadRequest, "Invalid multipart upload", err)
+	default:
+		_ = c.Error(err)
+		writeProblem(c, http.StatusInternalServerError, "Internal server error", nil)
+	}
+}
+
+func toPresignedPartsResponse(parts []command.PresignedPart) []presignedPartResponse {
+	resp := make([]presignedPartResponse, 0, len(parts))
+	for _, p := range parts {
+		resp = append(resp, presignedPartResponse{PartNumber: p.PartNumber, URL: p.URL})
+	}
+	return resp
+}
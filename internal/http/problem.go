@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/observability"
+	"github.com/Sokol111/ecommerce-image-service-api/api"
+	"github.com/gin-gonic/gin"
+)
+
+// problemResponse renders an application/problem+json body for statuses
+// that are not declared as typed responses in the API contract.
+type problemResponse api.Problem
+
+func newProblem(ctx context.Context, status int, title string, detail string) problemResponse {
+	traceId := observability.GetTraceId(ctx)
+	p := problemResponse{
+		Title:   title,
+		Status:  status,
+		TraceId: &traceId,
+	}
+	if detail != "" {
+		p.Detail = &detail
+	}
+	return p
+}
+
+// writeProblem writes a problem response from a plain gin handler
+func writeProblem(c *gin.Context, status int, title string, err error) {
+	detail := ""
+	if err != nil {
+		detail = err.Error()
+	}
+	_ = newProblem(c, status, title, detail).write(c.Writer)
+}
+
+func (r problemResponse) write(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(r.Status)
+	return json.NewEncoder(w).Encode(r)
+}
+
+func (r problemResponse) VisitListImagesResponse(w http.ResponseWriter) error {
+	return r.write(w)
+}
+
+func (r problemResponse) VisitUpdateImageResponse(w http.ResponseWriter) error {
+	return r.write(w)
+}
+
+func (r problemResponse) VisitProcessImageResponse(w http.ResponseWriter) error {
+	return r.write(w)
+}
+
+func (r problemResponse) VisitConfirmUploadResponse(w http.ResponseWriter) error {
+	return r.write(w)
+}
+
+func (r problemResponse) VisitCreatePresignResponse(w http.ResponseWriter) error {
+	return r.write(w)
+}
+
+func (r problemResponse) VisitGetDeliveryUrlResponse(w http.ResponseWriter) error {
+	return r.write(w)
+}
+
+func (r problemResponse) VisitDeleteImageResponse(w http.ResponseWriter) error {
+	return r.write(w)
+}
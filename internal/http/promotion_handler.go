@@ -0,0 +1,187 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/command"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/query"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/promotion"
+	"github.com/gin-gonic/gin"
+)
+
+// Promotion jobs move draft images to a product step by step. Operators inspect and retry
+// failed jobs here; asynchronous promotions are polled here.
+
+type promotionItemResponse struct {
+	ImageID   string `json:"imageId"`
+	SourceKey string `json:"sourceKey"`
+	TargetKey string `json:"targetKey"`
+	Step      string `json:"step"`
+	Error     string `json:"error,omitempty"`
+}
+
+type promotionJobResponse struct {
+	ID         string                  `json:"id"`
+	DraftID    string                  `json:"draftId"`
+	ProductID  string                  `json:"productId"`
+	Status     string                  `json:"status"`
+	Attempts   int                     `json:"attempts"`
+	LastError  string                  `json:"lastError,omitempty"`
+	Items      []promotionItemResponse `json:"items"`
+	CreatedAt  time.Time               `json:"createdAt"`
+	ModifiedAt time.Time               `json:"modifiedAt"`
+}
+
+type promotionJobListResponse struct {
+	Items []promotionJobResponse `json:"items"`
+}
+
+type promotionHandler struct {
+	getHandler   query.GetPromotionJobQueryHandler
+	listHandler  query.ListPromotionJobsQueryHandler
+	retryHandler command.RetryPromotionCommandHandler
+}
+
+func newPromotionHandler(get query.GetPromotionJobQueryHandler, list query.ListPromotionJobsQueryHandler, retry command.RetryPromotionCommandHandler) *promotionHandler {
+	return &promotionHandler{
+		getHandler:   get,
+		listHandler:  list,
+		retryHandler: retry,
+	}
+}
+
+func (h *promotionHandler) register(r gin.IRouter) {
+	r.GET("/promotions", h.list)
+	r.GET("/promotions/:id", h.get)
+	r.POST("/promotions/:id/retry", h.retry)
+}
+
+func (h *promotionHandler) list(c *gin.Context) {
+	q := query.ListPromotionJobsQuery{
+		Status: c.Query("status"),
+	}
+	if v, ok := c.GetQuery("limit"); ok {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			writeProblem(c, http.StatusBadRequest, "Invalid request", fmt.Errorf("invalid limit: %w", err))
+			return
+		}
+		q.Limit = &limit
+	}
+
+	jobs, err := h.listHandler.Handle(c, q)
+	if err != nil {
+		if errors.Is(err, image.ErrInvalidSearchCriteria) {
+			writeProblem(c, http.StatusBadRequest, "Invalid request", err)
+			return
+		}
+		_ = c.Error(err)
+		writeProblem(c, http.StatusInternalServerError, "Internal server error", nil)
+		return
+	}
+
+	items := make([]promotionJobResponse, 0, len(jobs))
+	for _, job := range jobs {
+		items = append(items, toPromotionJobResponse(job))
+	}
+
+	c.JSON(http.StatusOK, promotionJobListResponse{Items: items})
+}
+
+func (h *promotionHandler) get(c *gin.Context) {
+	job, err := h.getHandler.Handle(c, query.GetPromotionJobQuery{ID: c.Param("id")})
+	if err != nil {
+		if errors.Is(err, promotion.ErrJobNotFound) {
+			writeProblem(c, http.StatusNotFound, "Promotion job not found", err)
+			return
+		}
+		_ = c.Error(err)
+		writeProblem(c, http.StatusInternalServerError, "Internal server error", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPromotionJobResponse(job))
+}
+
+func (h *promotionHandler) retry(c *gin.Context) {
+	job, err := h.retryHandler.Handle(c, command.RetryPromotionCommand{JobID: c.Param("id")})
+	if err != nil {
+		switch {
+		case errors.Is(err, promotion.ErrJobNotFound):
+			writeProblem(c, http.StatusNotFound, "Promotion job not found", err)
+		case errors.Is(err, promotion.ErrJobNotFailed):
+			writeProblem(c, http.StatusConflict, "Promotion job is not failed", err)
+		default:
+			_ = c.Error(fmt.Errorf("failed to retry promotion job: %w", err))
+			writeProblem(c, http.StatusInternalServerError, "Internal server error", nil)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toPromotionJobResponse(job))
+}
+
+func toPromotionJobResponse(job *promotion.Job) promotionJobResponse {
+	items := make([]promotionItemResponse, 0, len(job.Items))
+	for _, item := range job.Items {
+		items = append(items, promotionItemResponse{
+			ImageID:   item.ImageID,
+			SourceKey: item.SourceKey,
+			TargetKey: item.TargetKey,
+			Step:      string(item.Step),
+			Error:     item.Error,
+		})
+	}
+
+	return promotionJobResponse{
+		ID:         job.ID,
+		DraftID:    job.DraftID,
+		ProductID:  job.ProductID,
+		Status:     string(job.Status),
+		Attempts:   job.Attempts,
+		LastError:  job.LastError,
+		Items:      items,
+		CreatedAt:  job.CreatedAt,
+		ModifiedAt: job.ModifiedAt,
+	}
+}
+
+// preferAsync reports whether the client sent "Prefer: respond-async" (RFC 7240)
+func preferAsync(ctx context.Context) bool {
+	for _, pref := range strings.Split(requestHeader(ctx, "Prefer"), ",") {
+		if strings.EqualFold(strings.TrimSpace(pref), "respond-async") {
+			return true
+		}
+	}
+	return false
+}
+
+// promotionAcceptedResponse answers an asynchronous PromoteImages request with the jobs to poll
+type promotionAcceptedResponse struct {
+	Jobs []promotionJobResponse `json:"jobs"`
+}
+
+func newPromotionAccepted(jobs []*promotion.Job) promotionAcceptedResponse {
+	accepted := promotionAcceptedResponse{Jobs: make([]promotionJobResponse, 0, len(jobs))}
+	for _, job := range jobs {
+		accepted.Jobs = append(accepted.Jobs, toPromotionJobResponse(job))
+	}
+	return accepted
+}
+
+func (r promotionAcceptedResponse) VisitPromoteImagesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	if len(r.Jobs) > 0 {
+		w.Header().Set("Location", "/promotions/"+r.Jobs[len(r.Jobs)-1].ID)
+	}
+	w.WriteHeader(http.StatusAccepted)
+	return json.NewEncoder(w).Encode(r)
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/query"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/gin-gonic/gin"
+)
+
+// renderHints are the request headers the render redirect depends on
+var renderHints = []string{"Sec-CH-DPR", "Sec-CH-Width", "Save-Data"}
+
+// renderHandler redirects a plain <img src> to the variant that suits the client best
+type renderHandler struct {
+	getHandler query.GetRenderURLQueryHandler
+}
+
+func newRenderHandler(get query.GetRenderURLQueryHandler) *renderHandler {
+	return &renderHandler{getHandler: get}
+}
+
+func (h *renderHandler) register(r gin.IRouter) {
+	r.GET("/images/:id/render", h.render)
+}
+
+func (h *renderHandler) render(c *gin.Context) {
+	accepted := acceptedTypes(c.GetHeader("Accept"))
+	q := query.GetRenderURLQuery{
+		ImageID:     c.Param("id"),
+		Caller:      callerName(c),
+		AcceptsAVIF: accepted["image/avif"],
+		AcceptsWebP: accepted["image/webp"],
+		SaveData:    strings.EqualFold(strings.TrimSpace(c.GetHeader("Save-Data")), "on"),
+	}
+	// Malformed hints are ignored like missing ones; the page cannot fix them
+	if v, err := strconv.ParseFloat(c.GetHeader("Sec-CH-DPR"), 32); err == nil && v > 0 {
+		q.DPR = float32(v)
+	}
+	if v, err := strconv.Atoi(c.GetHeader("Sec-CH-Width")); err == nil && v > 0 {
+		q.Width = v
+	}
+	if v, ok := c.GetQuery("w"); ok {
+		w, err := strconv.Atoi(v)
+		if err != nil || w <= 0 {
+			writeProblem(c, http.StatusBadRequest, "Invalid request", fmt.Errorf("invalid w: %s", v))
+			return
+		}
+		q.LayoutWidth = w
+	}
+	if v, ok := c.GetQuery("fit"); ok {
+		q.Fit = &v
+	}
+
+	result, err := h.getHandler.Handle(c, q)
+	if err != nil {
+		switch {
+		case errors.Is(err, image.ErrImageNotFound):
+			writeProblem(c, http.StatusNotFound, "Image not found", err)
+		case errors.Is(err, image.ErrInvalidDeliveryPolicy):
+			writeProblem(c, http.StatusBadRequest, "Invalid request", err)
+		default:
+			_ = c.Error(err)
+			writeProblem(c, http.StatusInternalServerError, "Internal server error", nil)
+		}
+		return
+	}
+
+	// Ask browsers for the hints on later requests, and keep caches from serving
+	// one client's choice to another. The watermark depends on the caller, so the
+	// redirect of an authenticated caller is never stored by shared caches.
+	c.Header("Accept-CH", strings.Join(renderHints, ", "))
+	c.Header("Vary", "Accept, Authorization, "+strings.Join(renderHints, ", "))
+	visibility := "public"
+	if q.Caller != "" {
+		visibility = "private"
+	}
+	c.Header("Cache-Control", fmt.Sprintf("%s, max-age=%d", visibility, int(result.MaxAge.Seconds())))
+	c.Redirect(http.StatusFound, result.URL)
+}
+
+// acceptedTypes returns the media types of an Accept header the client accepts. Types sent
+// with q=0 are explicitly refused; a malformed quality counts as the default of 1.
+func acceptedTypes(header string) map[string]bool {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mediaType == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range params[1:] {
+			name, value, ok := strings.Cut(param, "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+				continue
+			}
+			if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				quality = v
+			}
+		}
+		accepted[mediaType] = quality > 0
+	}
+	return accepted
+}
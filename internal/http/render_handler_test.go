@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/query"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAcceptedTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantAVIF bool
+		wantWebP bool
+	}{
+		{"empty", "", false, false},
+		{"browser default", "image/avif,image/webp,image/apng,image/*,*/*;q=0.8", true, true},
+		{"wildcards only", "image/*,*/*", false, false},
+		{"explicit refusal", "image/avif;q=0, image/webp;q=0.5", false, true},
+		{"refusal with spaces and decimals", "image/webp ; q = 0.000", false, false},
+		{"case insensitive", "Image/AVIF;Q=0.9", true, false},
+		{"malformed quality counts as accepted", "image/webp;q=high", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accepted := acceptedTypes(tt.header)
+			if accepted["image/avif"] != tt.wantAVIF || accepted["image/webp"] != tt.wantWebP {
+				t.Errorf("avif = %v, webp = %v, want %v, %v", accepted["image/avif"], accepted["image/webp"], tt.wantAVIF, tt.wantWebP)
+			}
+		})
+	}
+}
+
+// stubRenderHandler answers every render query with a fixed URL
+type stubRenderHandler struct{}
+
+func (stubRenderHandler) Handle(_ context.Context, q query.GetRenderURLQuery) (*query.GetRenderURLResult, error) {
+	return &query.GetRenderURLResult{URL: "https://img.example.com/" + q.Caller, MaxAge: time.Hour}, nil
+}
+
+func TestRenderCaching(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(authenticateCaller(Config{Callers: []CallerCredential{{Name: "storefront", Token: "s3cret"}}}))
+	newRenderHandler(stubRenderHandler{}).register(engine)
+
+	tests := []struct {
+		name             string
+		header           string
+		wantCacheControl string
+	}{
+		{"anonymous redirect is shared", "", "public, max-age=3600"},
+		{"caller's redirect stays private", "Bearer s3cret", "private, max-age=3600"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/images/img-1/render", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusFound {
+				t.Fatalf("status: got %d, want %d", rec.Code, http.StatusFound)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != tt.wantCacheControl {
+				t.Fatalf("Cache-Control: got %q, want %q", got, tt.wantCacheControl)
+			}
+			if vary := rec.Header().Get("Vary"); !strings.Contains(vary, "Authorization") {
+				t.Fatalf("Vary does not list Authorization: %q", vary)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/query"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/gin-gonic/gin"
+)
+
+// The signature check supports signing key rotation: it tells whether URLs still in
+// circulation were signed with a retiring key. It is registered under /admin, which
+// only admin callers may reach.
+
+type signatureResponse struct {
+	Valid  bool    `json:"valid"`
+	KeyID  *string `json:"keyId,omitempty"`
+	Active bool    `json:"active"`
+}
+
+type signatureHandler struct {
+	verifyHandler query.VerifyDeliveryURLQueryHandler
+}
+
+func newSignatureHandler(verify query.VerifyDeliveryURLQueryHandler) *signatureHandler {
+	return &signatureHandler{verifyHandler: verify}
+}
+
+func (h *signatureHandler) register(r gin.IRouter) {
+	r.GET("/signatures", h.verify)
+}
+
+func (h *signatureHandler) verify(c *gin.Context) {
+	check, err := h.verifyHandler.Handle(c, query.VerifyDeliveryURLQuery{URL: c.Query("url")})
+	if err != nil {
+		switch {
+		case errors.Is(err, image.ErrMalformedDeliveryURL):
+			writeProblem(c, http.StatusBadRequest, "Invalid request", err)
+		default:
+			_ = c.Error(err)
+			writeProblem(c, http.StatusInternalServerError, "Internal server error", nil)
+		}
+		return
+	}
+
+	resp := signatureResponse{Valid: check.Valid, Active: check.Active}
+	if check.KeyID != "" {
+		resp.KeyID = &check.KeyID
+	}
+	c.JSON(http.StatusOK, resp)
+}
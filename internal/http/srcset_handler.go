@@ -0,0 +1,131 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/query"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/gin-gonic/gin"
+)
+
+// A srcset response carries everything a <picture> element needs in a single round trip.
+
+type srcsetCandidateResponse struct {
+	URL   string `json:"url"`
+	Width int    `json:"width"`
+}
+
+type srcsetSourceResponse struct {
+	Format     string                    `json:"format"`
+	Type       string                    `json:"type"`
+	Srcset     string                    `json:"srcset"`
+	Candidates []srcsetCandidateResponse `json:"candidates"`
+}
+
+type srcsetResponse struct {
+	Width     *int                   `json:"width,omitempty"`
+	Height    *int                   `json:"height,omitempty"`
+	Sources   []srcsetSourceResponse `json:"sources"`
+	Src       string                 `json:"src"`
+	ExpiresAt *time.Time             `json:"expiresAt,omitempty"`
+}
+
+type srcsetHandler struct {
+	getHandler query.GetSrcsetQueryHandler
+}
+
+func newSrcsetHandler(get query.GetSrcsetQueryHandler) *srcsetHandler {
+	return &srcsetHandler{getHandler: get}
+}
+
+func (h *srcsetHandler) register(r gin.IRouter) {
+	r.GET("/images/:id/srcset", h.get)
+}
+
+func (h *srcsetHandler) get(c *gin.Context) {
+	q := query.GetSrcsetQuery{
+		ImageID: c.Param("id"),
+		Caller:  callerName(c),
+	}
+	if v, ok := c.GetQuery("widths"); ok {
+		for _, part := range strings.Split(v, ",") {
+			w, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				writeProblem(c, http.StatusBadRequest, "Invalid request", fmt.Errorf("invalid widths: %w", err))
+				return
+			}
+			q.Widths = append(q.Widths, w)
+		}
+	}
+	if v, ok := c.GetQuery("formats"); ok {
+		q.Formats = strings.Split(v, ",")
+	}
+	if v, ok := c.GetQuery("fit"); ok {
+		q.Fit = &v
+	}
+	if v, ok := c.GetQuery("quality"); ok {
+		quality, err := strconv.Atoi(v)
+		if err != nil || quality < 1 || quality > 100 {
+			writeProblem(c, http.StatusBadRequest, "Invalid request", fmt.Errorf("invalid quality: %s", v))
+			return
+		}
+		q.Quality = &quality
+	}
+	if v, ok := c.GetQuery("ttlSeconds"); ok {
+		ttl, err := strconv.Atoi(v)
+		if err != nil || ttl <= 0 {
+			writeProblem(c, http.StatusBadRequest, "Invalid request", fmt.Errorf("invalid ttlSeconds: %s", v))
+			return
+		}
+		t := time.Now().Add(time.Duration(ttl) * time.Second)
+		q.Expires = &t
+	}
+
+	result, err := h.getHandler.Handle(c, q)
+	if err != nil {
+		switch {
+		case errors.Is(err, image.ErrImageNotFound):
+			writeProblem(c, http.StatusNotFound, "Image not found", err)
+		case errors.Is(err, image.ErrInvalidDeliveryPolicy):
+			writeProblem(c, http.StatusBadRequest, "Invalid request", err)
+		default:
+			_ = c.Error(err)
+			writeProblem(c, http.StatusInternalServerError, "Internal server error", nil)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toSrcsetResponse(result))
+}
+
+func toSrcsetResponse(result *query.GetSrcsetResult) srcsetResponse {
+	out := srcsetResponse{
+		Sources:   make([]srcsetSourceResponse, 0, len(result.Sources)),
+		Src:       result.FallbackURL,
+		ExpiresAt: result.ExpiresAt,
+	}
+	if result.Width > 0 && result.Height > 0 {
+		out.Width = &result.Width
+		out.Height = &result.Height
+	}
+
+	for _, source := range result.Sources {
+		candidates := make([]srcsetCandidateResponse, 0, len(source.Candidates))
+		for _, cand := range source.Candidates {
+			candidates = append(candidates, srcsetCandidateResponse{URL: cand.URL, Width: cand.Width})
+		}
+		out.Sources = append(out.Sources, srcsetSourceResponse{
+			Format:     source.Format,
+			Type:       source.Type,
+			Srcset:     source.Srcset,
+			Candidates: candidates,
+		})
+	}
+
+	return out
+}
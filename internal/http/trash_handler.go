@@ -0,0 +1,44 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/command"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/gin-gonic/gin"
+)
+
+// Soft deleted images stay in the trash, restorable, until the retention purge removes them.
+// The trash itself is listed with ListImages and status=deleted.
+
+type trashHandler struct {
+	restoreHandler command.RestoreImageCommandHandler
+}
+
+func newTrashHandler(restore command.RestoreImageCommandHandler) *trashHandler {
+	return &trashHandler{restoreHandler: restore}
+}
+
+func (h *trashHandler) register(r gin.IRouter) {
+	r.POST("/images/:id/restore", h.restore)
+}
+
+func (h *trashHandler) restore(c *gin.Context) {
+	img, err := h.restoreHandler.Handle(c, command.RestoreImageCommand{ImageID: c.Param("id")})
+	if err != nil {
+		switch {
+		case errors.Is(err, image.ErrImageNotFound):
+			writeProblem(c, http.StatusNotFound, "Image not found", err)
+		case errors.Is(err, image.ErrImageNotDeleted):
+			writeProblem(c, http.StatusConflict, "Image is not deleted", err)
+		default:
+			_ = c.Error(fmt.Errorf("failed to restore image: %w", err))
+			writeProblem(c, http.StatusInternalServerError, "Internal server error", nil)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPI(img))
+}
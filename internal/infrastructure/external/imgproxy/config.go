@@ -4,20 +4,57 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	PublicBaseURL  string `mapstructure:"public-base-url"` // IMGPROXY_PUBLIC_BASE_URL
-	KeyHex         string `mapstructure:"key-hex"`         // IMGPROXY_KEY_HEX
-	SaltHex        string `mapstructure:"salt-hex"`        // IMGPROXY_SALT_HEX
+	PublicBaseURL   string `mapstructure:"public-base-url"`   // IMGPROXY_PUBLIC_BASE_URL
+	InternalBaseURL string `mapstructure:"internal-base-url"` // imgproxy address reachable from the service; defaults to PublicBaseURL
+	KeyHex          string `mapstructure:"key-hex"`           // IMGPROXY_KEY_HEX; single pair, used when Keys is empty
+	SaltHex         string `mapstructure:"salt-hex"`          // IMGPROXY_SALT_HEX
+	// Keys lists the key/salt pairs in the order of imgproxy's IMGPROXY_KEY/IMGPROXY_SALT lists.
+	// To rotate: add the new pair to imgproxy and here, switch ActiveKey to it once imgproxy is
+	// deployed, and drop the old pair after the URLs signed with it have expired from caches.
+	Keys             []SigningKey `mapstructure:"keys"`
+	ActiveKey        string       `mapstructure:"active-key"`        // ID of the pair new URLs are signed with; defaults to the first
+	DerivativeWidths []int        `mapstructure:"derivative-widths"` // widths rendered during processing
+	// Watermarks are matched in order against delivery URLs; the first matching policy applies
+	Watermarks     []WatermarkPolicy `mapstructure:"watermarks"`
 	DefaultQuality int
-	Key            []byte
+	Key            []byte // active pair
 	Salt           []byte
 }
 
+// SigningKey is one imgproxy key/salt pair
+type SigningKey struct {
+	ID      string `mapstructure:"id"`
+	KeyHex  string `mapstructure:"key-hex"`
+	SaltHex string `mapstructure:"salt-hex"`
+	Key     []byte
+	Salt    []byte
+}
+
+// WatermarkPolicy selects the delivery URLs that get a watermark (imgproxy wm and wmu)
+type WatermarkPolicy struct {
+	OwnerTypes    []string `mapstructure:"owner-types"`    // empty matches every owner type
+	Presets       []string `mapstructure:"presets"`        // empty matches URLs with and without a preset
+	ExemptCallers []string `mapstructure:"exempt-callers"` // authenticated callers never watermarked, e.g. the storefront
+	Opacity       float64  `mapstructure:"opacity"`        // 0..1, defaults to 1
+	Position      string   `mapstructure:"position"`       // gravity type or "re" to replicate; defaults to "ce"
+	XOffset       float64  `mapstructure:"x-offset"`
+	YOffset       float64  `mapstructure:"y-offset"`
+	Scale         float64  `mapstructure:"scale"` // relative to the result width; 0 keeps the watermark size
+	URL           string   `mapstructure:"url"`   // custom watermark image; the imgproxy default when empty
+}
+
+var watermarkPositions = map[string]bool{
+	"ce": true, "no": true, "so": true, "ea": true, "we": true,
+	"noea": true, "nowe": true, "soea": true, "sowe": true, "re": true,
+}
+
 func newConfig(v *viper.Viper) (Config, error) {
 	var cfg Config
 	if err := v.Sub("imgproxy").UnmarshalExact(&cfg); err != nil {
@@ -27,18 +64,98 @@ func newConfig(v *viper.Viper) (Config, error) {
 		return cfg, errors.New("imgproxy public base URL is required")
 	}
 	cfg.PublicBaseURL = strings.TrimRight(cfg.PublicBaseURL, "/")
+	if cfg.InternalBaseURL == "" {
+		cfg.InternalBaseURL = cfg.PublicBaseURL
+	}
+	cfg.InternalBaseURL = strings.TrimRight(cfg.InternalBaseURL, "/")
+	if len(cfg.DerivativeWidths) == 0 {
+		cfg.DerivativeWidths = []int{320, 640, 1280}
+	}
 
-	key, err := hex.DecodeString(cfg.KeyHex)
-	if err != nil {
-		return cfg, fmt.Errorf("failed to decode key: %w", err)
+	for i := range cfg.Watermarks {
+		if err := cfg.Watermarks[i].normalize(); err != nil {
+			return cfg, fmt.Errorf("invalid watermark policy %d: %w", i, err)
+		}
 	}
-	cfg.Key = key
-	salt, err := hex.DecodeString(cfg.SaltHex)
-	if err != nil {
-		return cfg, fmt.Errorf("failed to decode salt: %w", err)
+
+	if err := cfg.loadKeys(); err != nil {
+		return cfg, err
 	}
-	cfg.Salt = salt
 	cfg.DefaultQuality = 80
 
 	return cfg, nil
 }
+
+// loadKeys decodes the signing keys and selects the active pair
+func (cfg *Config) loadKeys() error {
+	if len(cfg.Keys) == 0 {
+		cfg.Keys = []SigningKey{{ID: "default", KeyHex: cfg.KeyHex, SaltHex: cfg.SaltHex}}
+	} else if cfg.KeyHex != "" || cfg.SaltHex != "" {
+		return errors.New("imgproxy key-hex and salt-hex cannot be combined with keys")
+	}
+
+	ids := make(map[string]bool, len(cfg.Keys))
+	for i := range cfg.Keys {
+		k := &cfg.Keys[i]
+		if k.ID == "" {
+			return fmt.Errorf("imgproxy key %d has no id", i)
+		}
+		if ids[k.ID] {
+			return fmt.Errorf("duplicate imgproxy key id: %s", k.ID)
+		}
+		ids[k.ID] = true
+
+		key, err := hex.DecodeString(k.KeyHex)
+		if err != nil {
+			return fmt.Errorf("failed to decode key %s: %w", k.ID, err)
+		}
+		k.Key = key
+		salt, err := hex.DecodeString(k.SaltHex)
+		if err != nil {
+			return fmt.Errorf("failed to decode salt %s: %w", k.ID, err)
+		}
+		k.Salt = salt
+	}
+
+	if cfg.ActiveKey == "" {
+		cfg.ActiveKey = cfg.Keys[0].ID
+	}
+	i := slices.IndexFunc(cfg.Keys, func(k SigningKey) bool { return k.ID == cfg.ActiveKey })
+	if i < 0 {
+		return fmt.Errorf("active imgproxy key not found: %s", cfg.ActiveKey)
+	}
+	cfg.Key = cfg.Keys[i].Key
+	cfg.Salt = cfg.Keys[i].Salt
+	return nil
+}
+
+func (p *WatermarkPolicy) normalize() error {
+	if p.Opacity == 0 {
+		p.Opacity = 1
+	}
+	if p.Opacity < 0 || p.Opacity > 1 {
+		return fmt.Errorf("opacity must be between 0 and 1, got %v", p.Opacity)
+	}
+	if p.Position == "" {
+		p.Position = "ce"
+	}
+	if !watermarkPositions[p.Position] {
+		return fmt.Errorf("unsupported position: %s", p.Position)
+	}
+	if p.Scale < 0 {
+		return fmt.Errorf("scale must not be negative, got %v", p.Scale)
+	}
+	return nil
+}
+
+// matches reports whether the policy applies to a URL. Exemptions only go by the
+// authenticated caller, never by anything a client can pick, like the preset.
+func (p WatermarkPolicy) matches(ownerType, preset, caller string) bool {
+	if caller != "" && slices.Contains(p.ExemptCallers, caller) {
+		return false
+	}
+	if len(p.OwnerTypes) > 0 && !slices.Contains(p.OwnerTypes, ownerType) {
+		return false
+	}
+	return len(p.Presets) == 0 || slices.Contains(p.Presets, preset)
+}
@@ -0,0 +1,69 @@
+package imgproxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/Sokol111/ecommerce-image-service/internal/infrastructure/external/s3"
+)
+
+// derivativeGenerator requests the standard widths from imgproxy so that
+// they are rendered (and cached downstream) before the image is marked ready.
+type derivativeGenerator struct {
+	signer *signer
+	widths []int
+	client *http.Client
+}
+
+func newDerivativeGenerator(cfg Config, s3cfg s3.Config) abstraction.DerivativeGenerator {
+	return &derivativeGenerator{
+		signer: &signer{
+			baseURL: cfg.InternalBaseURL,
+			bucket:  s3cfg.Bucket,
+			key:     cfg.Key,
+			salt:    cfg.Salt,
+		},
+		widths: cfg.DerivativeWidths,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (g *derivativeGenerator) Generate(ctx context.Context, key string) error {
+	for _, w := range g.widths {
+		width := w
+		url := g.signer.BuildURL(key, abstraction.SignerOptions{Width: &width})
+		if err := g.fetch(ctx, url); err != nil {
+			return fmt.Errorf("width %d: %w", width, err)
+		}
+	}
+	return nil
+}
+
+func (g *derivativeGenerator) fetch(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusUnprocessableEntity, resp.StatusCode == http.StatusNotFound:
+		// imgproxy answers 422 when the source is not a processable image and 404 when it is missing
+		return fmt.Errorf("%w: imgproxy responded %d", image.ErrImageRejected, resp.StatusCode)
+	default:
+		return fmt.Errorf("imgproxy responded %d", resp.StatusCode)
+	}
+}
@@ -8,5 +8,7 @@ func NewImgProxyModule() fx.Option {
 	return fx.Provide(
 		newConfig,
 		newImgproxySigner,
+		newDerivativeGenerator,
+		newPerceptualHasher,
 	)
 }
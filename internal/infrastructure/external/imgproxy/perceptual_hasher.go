@@ -0,0 +1,69 @@
+package imgproxy
+
+import (
+	"context"
+	"fmt"
+	"image/png"
+	"net/http"
+	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/Sokol111/ecommerce-image-service/internal/infrastructure/external/s3"
+)
+
+// perceptualHasher lets imgproxy decode and downscale the source, whatever its format,
+// and hashes the tiny PNG it returns
+type perceptualHasher struct {
+	signer *signer
+	client *http.Client
+}
+
+func newPerceptualHasher(cfg Config, s3cfg s3.Config) abstraction.PerceptualHasher {
+	return &perceptualHasher{
+		signer: &signer{
+			baseURL: cfg.InternalBaseURL,
+			bucket:  s3cfg.Bucket,
+			key:     cfg.Key,
+			salt:    cfg.Salt,
+		},
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (h *perceptualHasher) Hash(ctx context.Context, key string) (string, error) {
+	width, height := image.DHashWidth, image.DHashHeight
+	fit, format := "force", "png"
+	url := h.signer.BuildURL(key, abstraction.SignerOptions{
+		Width:  &width,
+		Height: &height,
+		Fit:    &fit,
+		Format: &format,
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+	case resp.StatusCode == http.StatusUnprocessableEntity, resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: imgproxy responded %d", image.ErrImageRejected, resp.StatusCode)
+	default:
+		return "", fmt.Errorf("imgproxy responded %d", resp.StatusCode)
+	}
+
+	thumb, err := png.Decode(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("decode thumbnail: %w", err)
+	}
+
+	return image.DHash(thumb), nil
+}
@@ -9,22 +9,29 @@ import (
 	"strings"
 
 	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
 	"github.com/Sokol111/ecommerce-image-service/internal/infrastructure/external/s3"
 )
 
 type signer struct {
-	publicBaseURL string
-	bucket        string
-	key           []byte
-	salt          []byte
+	baseURL    string
+	bucket     string
+	key        []byte
+	salt       []byte
+	watermarks []WatermarkPolicy // empty for the internal signers, whose renders must stay clean
+	keys       []SigningKey      // every accepted pair, for verification
+	activeKey  string
 }
 
 func newImgproxySigner(cfg Config, s3cfg s3.Config) (abstraction.ImgproxySigner, error) {
 	return &signer{
-		publicBaseURL: cfg.PublicBaseURL,
-		bucket:        s3cfg.Bucket,
-		key:           cfg.Key,
-		salt:          cfg.Salt,
+		baseURL:    cfg.PublicBaseURL,
+		bucket:     s3cfg.Bucket,
+		key:        cfg.Key,
+		salt:       cfg.Salt,
+		watermarks: cfg.Watermarks,
+		keys:       cfg.Keys,
+		activeKey:  cfg.ActiveKey,
 	}, nil
 }
 
@@ -32,10 +39,125 @@ func (s *signer) BuildURL(key string, opts abstraction.SignerOptions) string {
 	// 1) Build S3 source URL (s3://bucket/key)
 	source := fmt.Sprintf("s3://%s/%s", s.bucket, key)
 
-	// 2) Processing options
+	// 2) Processing options, always in the same order so that equal options give equal URLs
+	processing := "/" + strings.Join(processingOptions(opts, s.watermark(opts)), "/")
+
+	// 3) Source (plain) from S3 URL
+	src := "/plain/" + source
+
+	// 4) Extension (@format) — опційно
+	suffix := ""
+	if opts.Format != nil && *opts.Format != "" {
+		ext := strings.TrimPrefix(strings.ToLower(*opts.Format), ".")
+		switch ext {
+		case "webp", "avif", "jpeg", "jpg", "png":
+			if ext == "jpg" {
+				ext = "jpeg"
+			}
+			suffix = "@" + ext
+		}
+	}
+
+	// 5) Підписуємо повний path (починається з /)
+	path := processing + src + suffix
+	sig := s.sign(path)
+
+	return s.baseURL + "/" + sig + path
+}
+
+// processingOptions serializes the options in a fixed order. Options that existed first
+// come first, so URLs built without the newer options stay unchanged.
+func processingOptions(opts abstraction.SignerOptions, wm *WatermarkPolicy) []string {
 	var parts []string
 
-	// rs (resize meta): rs:%type:%w:%h
+	// pr (preset): options defined on the imgproxy side; the ones below override it
+	preset := opts.Preset != nil && *opts.Preset != ""
+	if preset {
+		parts = append(parts, "pr:"+*opts.Preset)
+	}
+
+	// rs (resize meta): rs:%type:%w:%h; a preset carries its own unless overridden
+	if !preset || opts.Fit != nil || opts.Width != nil || opts.Height != nil {
+		parts = append(parts, resizeOption(opts))
+	}
+
+	if opts.DPR != nil && *opts.DPR > 0 {
+		parts = append(parts, "dpr:"+formatFloat(float64(*opts.DPR)))
+	}
+	if opts.Quality != nil && *opts.Quality > 0 {
+		parts = append(parts, "q:"+strconv.Itoa(*opts.Quality))
+	}
+	if opts.Gravity != nil {
+		parts = append(parts, "g:"+gravityArgs(*opts.Gravity))
+	}
+	if opts.Crop != nil {
+		crop := "c:" + formatFloat(opts.Crop.Width) + ":" + formatFloat(opts.Crop.Height)
+		if opts.Crop.Gravity != nil {
+			crop += ":" + gravityArgs(*opts.Crop.Gravity)
+		}
+		parts = append(parts, crop)
+	}
+	if p := opts.Padding; p != nil {
+		parts = append(parts, fmt.Sprintf("pd:%d:%d:%d:%d", p.Top, p.Right, p.Bottom, p.Left))
+	}
+	if opts.Background != nil && *opts.Background != "" {
+		parts = append(parts, "bg:"+strings.TrimPrefix(strings.ToLower(*opts.Background), "#"))
+	}
+	if opts.Rotate != nil && *opts.Rotate != 0 {
+		parts = append(parts, "rot:"+strconv.Itoa(*opts.Rotate))
+	}
+	if f := opts.Flip; f != nil && (f.Horizontal || f.Vertical) {
+		parts = append(parts, "fl:"+boolArg(f.Horizontal)+":"+boolArg(f.Vertical))
+	}
+	if opts.Blur != nil && *opts.Blur > 0 {
+		parts = append(parts, "bl:"+formatFloat(float64(*opts.Blur)))
+	}
+	if opts.Sharpen != nil && *opts.Sharpen > 0 {
+		parts = append(parts, "sh:"+formatFloat(float64(*opts.Sharpen)))
+	}
+	if opts.Trim != nil && *opts.Trim > 0 {
+		parts = append(parts, "t:"+formatFloat(float64(*opts.Trim)))
+	}
+	if opts.StripMetadata != nil {
+		parts = append(parts, "sm:"+boolArg(*opts.StripMetadata))
+	}
+	if opts.StripColorProfile != nil {
+		parts = append(parts, "scp:"+boolArg(*opts.StripColorProfile))
+	}
+	if opts.CacheBuster != nil && *opts.CacheBuster != "" {
+		parts = append(parts, "cb:"+*opts.CacheBuster)
+	}
+	if wm != nil {
+		parts = append(parts, watermarkOptions(*wm)...)
+	}
+	if opts.Expires != nil && !opts.Expires.IsZero() {
+		parts = append(parts, fmt.Sprintf("exp:%d", opts.Expires.Unix()))
+	}
+
+	return parts
+}
+
+// watermark returns the first policy matching the options, if any
+func (s *signer) watermark(opts abstraction.SignerOptions) *WatermarkPolicy {
+	for i := range s.watermarks {
+		if s.watermarks[i].matches(opts.OwnerType, opts.DeliveryPreset, opts.Caller) {
+			return &s.watermarks[i]
+		}
+	}
+	return nil
+}
+
+// watermarkOptions formats wm:%opacity:%position:%x:%y:%scale and, for a custom image, wmu:%url
+func watermarkOptions(p WatermarkPolicy) []string {
+	parts := []string{"wm:" + formatFloat(p.Opacity) + ":" + p.Position + ":" +
+		formatFloat(p.XOffset) + ":" + formatFloat(p.YOffset) + ":" + formatFloat(p.Scale)}
+	if p.URL != "" {
+		parts = append(parts, "wmu:"+base64.RawURLEncoding.EncodeToString([]byte(p.URL)))
+	}
+	return parts
+}
+
+func resizeOption(opts abstraction.SignerOptions) string {
 	rt := "fit"
 	if opts.Fit != nil && *opts.Fit != "" {
 		rt = *opts.Fit
@@ -48,53 +170,69 @@ func (s *signer) BuildURL(key string, opts abstraction.SignerOptions) string {
 	if opts.Height != nil && *opts.Height > 0 {
 		h = strconv.Itoa(*opts.Height)
 	}
-	parts = append(parts, fmt.Sprintf("rs:%s:%s:%s", rt, w, h))
+	return fmt.Sprintf("rs:%s:%s:%s", rt, w, h)
+}
 
-	if opts.DPR != nil && *opts.DPR > 0 {
-		parts = append(parts, "dpr:"+trimFloat(*opts.DPR))
-	}
-	if opts.Quality != nil && *opts.Quality > 0 {
-		parts = append(parts, "q:"+strconv.Itoa(*opts.Quality))
-	}
-	if opts.Expires != nil && !opts.Expires.IsZero() {
-		parts = append(parts, fmt.Sprintf("exp:%d", opts.Expires.Unix()))
+// gravityArgs formats %type[:%x:%y]; offsets are omitted when both are zero
+func gravityArgs(g abstraction.Gravity) string {
+	if g.Type != abstraction.GravityFocalPoint && g.X == 0 && g.Y == 0 {
+		return g.Type
 	}
+	return g.Type + ":" + formatFloat(g.X) + ":" + formatFloat(g.Y)
+}
 
-	// 3) Source (plain) from S3 URL
-	processing := "/" + strings.Join(parts, "/")
-	src := "/plain/" + source
+func boolArg(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
 
-	// 4) Extension (@format) — опційно
-	suffix := ""
-	if opts.Format != nil && *opts.Format != "" {
-		ext := strings.TrimPrefix(strings.ToLower(*opts.Format), ".")
-		switch ext {
-		case "webp", "avif", "jpeg", "jpg", "png":
-			if ext == "jpg" {
-				ext = "jpeg"
-			}
-			suffix = "@" + ext
+// VerifyURL accepts a full URL or its path, /%signature/%processing/plain/%source
+func (s *signer) VerifyURL(url string) (abstraction.SignatureCheck, error) {
+	path := strings.TrimPrefix(url, s.baseURL)
+	if !strings.HasPrefix(path, "/") {
+		// a full URL, of imgproxy itself or of a CDN in front of it
+		_, rest, ok := strings.Cut(path, "://")
+		slash := strings.Index(rest, "/")
+		if !ok || slash < 0 {
+			return abstraction.SignatureCheck{}, fmt.Errorf("%w: no path", image.ErrMalformedDeliveryURL)
 		}
+		path = rest[slash:]
+	}
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
 	}
 
-	// 5) Підписуємо повний path (починається з /)
-	path := processing + src + suffix
-	sig := s.sign(path)
+	sig, signed, ok := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !ok || sig == "" || signed == "" {
+		return abstraction.SignatureCheck{}, fmt.Errorf("%w: no signature", image.ErrMalformedDeliveryURL)
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return abstraction.SignatureCheck{}, fmt.Errorf("%w: signature is not base64url: %v", image.ErrMalformedDeliveryURL, err)
+	}
 
-	return s.publicBaseURL + "/" + sig + path
+	for _, k := range s.keys {
+		if hmac.Equal(mac, computeMAC(k.Key, k.Salt, "/"+signed)) {
+			return abstraction.SignatureCheck{Valid: true, KeyID: k.ID, Active: k.ID == s.activeKey}, nil
+		}
+	}
+	return abstraction.SignatureCheck{}, nil
 }
 
 func (s *signer) sign(path string) string {
-	mac := hmac.New(sha256.New, s.key)
+	return base64.RawURLEncoding.EncodeToString(computeMAC(s.key, s.salt, path))
+}
+
+func computeMAC(key, salt []byte, path string) []byte {
+	mac := hmac.New(sha256.New, key)
 	// важливо: спочатку salt, потім сам path (з провідним "/")
-	mac.Write(s.salt)
+	mac.Write(salt)
 	mac.Write([]byte(path))
-	sum := mac.Sum(nil)
-	return base64.RawURLEncoding.EncodeToString(sum)
+	return mac.Sum(nil)
 }
 
-func trimFloat(f float32) string {
-	// красивий вигляд без зайвих нулів (1.5 → "1.5", 2 → "2")
-	x := strconv.FormatFloat(float64(f), 'f', -1, 64)
-	return strings.TrimRight(strings.TrimRight(x, "0"), ".")
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
 }
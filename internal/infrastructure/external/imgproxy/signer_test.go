@@ -0,0 +1,258 @@
+package imgproxy
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+	"github.com/Sokol111/ecommerce-image-service/internal/infrastructure/external/s3"
+)
+
+func newTestSigner(watermarks ...WatermarkPolicy) *signer {
+	for i := range watermarks {
+		_ = watermarks[i].normalize()
+	}
+	return &signer{
+		baseURL:    "https://img.example.com",
+		bucket:     "images",
+		key:        []byte("key"),
+		salt:       []byte("salt"),
+		watermarks: watermarks,
+	}
+}
+
+func TestBuildURLWatermark(t *testing.T) {
+	s := newTestSigner(WatermarkPolicy{
+		OwnerTypes:    []string{"product"},
+		ExemptCallers: []string{"storefront"},
+		Opacity:       0.5,
+		Position:      "soea",
+		Scale:         0.2,
+		URL:           "https://cdn.example.com/wm.png",
+	})
+
+	tests := []struct {
+		name string
+		opts abstraction.SignerOptions
+		want bool
+	}{
+		{"anonymous product", abstraction.SignerOptions{OwnerType: "product"}, true},
+		{"anonymous with the exempt caller's name as preset", abstraction.SignerOptions{OwnerType: "product", DeliveryPreset: "storefront"}, true},
+		{"other caller", abstraction.SignerOptions{OwnerType: "product", Caller: "partner"}, true},
+		{"exempt caller", abstraction.SignerOptions{OwnerType: "product", Caller: "storefront"}, false},
+		{"owner type without policy", abstraction.SignerOptions{OwnerType: "user"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := s.BuildURL("products/p-1/a.jpg", tt.opts)
+			got := strings.Contains(url, "/wm:0.5:soea:0:0:0.2/")
+			if got != tt.want {
+				t.Fatalf("watermark in %s: got %v, want %v", url, got, tt.want)
+			}
+			if got != strings.Contains(url, "/wmu:") {
+				t.Fatalf("custom watermark URL does not follow the watermark in %s", url)
+			}
+		})
+	}
+}
+
+func TestBuildURLWatermarkPresets(t *testing.T) {
+	s := newTestSigner(WatermarkPolicy{Presets: []string{"large"}})
+
+	if url := s.BuildURL("k.jpg", abstraction.SignerOptions{DeliveryPreset: "large"}); !strings.Contains(url, "/wm:1:ce:0:0:0/") {
+		t.Fatalf("preset URL is not watermarked: %s", url)
+	}
+	if url := s.BuildURL("k.jpg", abstraction.SignerOptions{}); strings.Contains(url, "/wm:") {
+		t.Fatalf("URL without the preset is watermarked: %s", url)
+	}
+}
+
+func TestBuildURLFloatOptions(t *testing.T) {
+	float := func(v float32) *float32 { return &v }
+	s := newTestSigner()
+
+	tests := []struct {
+		name string
+		opts abstraction.SignerOptions
+		want string
+	}{
+		{"whole blur keeps its zeros", abstraction.SignerOptions{Blur: float(10)}, "/bl:10/"},
+		{"maximum sharpen", abstraction.SignerOptions{Sharpen: float(100)}, "/sh:100/"},
+		{"trim threshold", abstraction.SignerOptions{Trim: float(20)}, "/t:20/"},
+		{"fractional blur", abstraction.SignerOptions{Blur: float(2.5)}, "/bl:2.5/"},
+		{"whole pixel ratio", abstraction.SignerOptions{DPR: float(2)}, "/dpr:2/"},
+		{"fractional pixel ratio", abstraction.SignerOptions{DPR: float(1.5)}, "/dpr:1.5/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if url := s.BuildURL("k.jpg", tt.opts); !strings.Contains(url, tt.want) {
+				t.Fatalf("%s does not contain %s", url, tt.want)
+			}
+		})
+	}
+}
+
+func TestInternalSignerNeverWatermarks(t *testing.T) {
+	s := newTestSigner()
+	if url := s.BuildURL("k.jpg", abstraction.SignerOptions{OwnerType: "product"}); strings.Contains(url, "/wm:") {
+		t.Fatalf("internal URL is watermarked: %s", url)
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        Config
+		wantErr    bool
+		wantActive string
+	}{
+		{
+			name:       "single pair",
+			cfg:        Config{KeyHex: "6b6579", SaltHex: "73616c74"},
+			wantActive: "default",
+		},
+		{
+			name: "first pair is active by default",
+			cfg: Config{Keys: []SigningKey{
+				{ID: "new", KeyHex: "6b6579", SaltHex: "73616c74"},
+				{ID: "old", KeyHex: "6f6c64", SaltHex: "73616c74"},
+			}},
+			wantActive: "new",
+		},
+		{
+			name: "explicit active pair",
+			cfg: Config{ActiveKey: "old", Keys: []SigningKey{
+				{ID: "new", KeyHex: "6b6579", SaltHex: "73616c74"},
+				{ID: "old", KeyHex: "6f6c64", SaltHex: "73616c74"},
+			}},
+			wantActive: "old",
+		},
+		{
+			name:    "single pair combined with keys",
+			cfg:     Config{KeyHex: "6b6579", Keys: []SigningKey{{ID: "new", KeyHex: "6b6579"}}},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate id",
+			cfg:     Config{Keys: []SigningKey{{ID: "a", KeyHex: "6b6579"}, {ID: "a", KeyHex: "6f6c64"}}},
+			wantErr: true,
+		},
+		{
+			name:    "key without id",
+			cfg:     Config{Keys: []SigningKey{{KeyHex: "6b6579"}}},
+			wantErr: true,
+		},
+		{
+			name:    "key that is not hex",
+			cfg:     Config{Keys: []SigningKey{{ID: "a", KeyHex: "key"}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown active pair",
+			cfg:     Config{ActiveKey: "b", Keys: []SigningKey{{ID: "a", KeyHex: "6b6579"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.loadKeys()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadKeys: err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.cfg.ActiveKey != tt.wantActive {
+				t.Fatalf("active key = %s, want %s", tt.cfg.ActiveKey, tt.wantActive)
+			}
+			i := slices.IndexFunc(tt.cfg.Keys, func(k SigningKey) bool { return k.ID == tt.wantActive })
+			if !bytes.Equal(tt.cfg.Key, tt.cfg.Keys[i].Key) || !bytes.Equal(tt.cfg.Salt, tt.cfg.Keys[i].Salt) {
+				t.Fatal("signing pair is not the active one")
+			}
+		})
+	}
+}
+
+func TestVerifyURLAcrossRotation(t *testing.T) {
+	keys := []SigningKey{
+		{ID: "new", KeyHex: "6e6577", SaltHex: "73616c74"},
+		{ID: "old", KeyHex: "6f6c64", SaltHex: "73616c74"},
+	}
+	newSigner := func(active string) abstraction.ImgproxySigner {
+		cfg := Config{PublicBaseURL: "https://img.example.com", ActiveKey: active, Keys: slices.Clone(keys)}
+		if err := cfg.loadKeys(); err != nil {
+			t.Fatalf("loadKeys: %v", err)
+		}
+		s, err := newImgproxySigner(cfg, s3.Config{Bucket: "images"})
+		if err != nil {
+			t.Fatalf("new signer: %v", err)
+		}
+		return s
+	}
+	current := newSigner("new")
+	retiring := newSigner("old")
+	width := 320
+
+	tests := []struct {
+		name    string
+		url     string
+		want    abstraction.SignatureCheck
+		wantErr error
+	}{
+		{
+			name: "signed with the active pair",
+			url:  current.BuildURL("products/p-1/a.jpg", abstraction.SignerOptions{Width: &width}),
+			want: abstraction.SignatureCheck{Valid: true, KeyID: "new", Active: true},
+		},
+		{
+			name: "signed with a retiring pair, behind a CDN",
+			url:  strings.Replace(retiring.BuildURL("products/p-1/a.jpg", abstraction.SignerOptions{}), "https://img.example.com", "https://cdn.example.com", 1) + "?v=1",
+			want: abstraction.SignatureCheck{Valid: true, KeyID: "old", Active: false},
+		},
+		{
+			name: "path only",
+			url:  strings.TrimPrefix(current.BuildURL("products/p-1/a.jpg", abstraction.SignerOptions{}), "https://img.example.com"),
+			want: abstraction.SignatureCheck{Valid: true, KeyID: "new", Active: true},
+		},
+		{
+			name: "signed with an unknown pair",
+			url:  newTestSigner().BuildURL("products/p-1/a.jpg", abstraction.SignerOptions{}),
+			want: abstraction.SignatureCheck{},
+		},
+		{
+			name:    "without signature",
+			url:     "https://img.example.com/",
+			wantErr: image.ErrMalformedDeliveryURL,
+		},
+		{
+			name:    "signature that is not base64url",
+			url:     "/not+base64/rs:fit:0:0/plain/s3://images/a.jpg",
+			wantErr: image.ErrMalformedDeliveryURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := current.VerifyURL(tt.url)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyURL(%s): %v", tt.url, err)
+			}
+			if got != tt.want {
+				t.Fatalf("VerifyURL(%s) = %+v, want %+v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package s3
+
+import (
+	"context"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func (o *objectStorage) CreateMultipartUpload(ctx context.Context, input *abstraction.CreateMultipartUploadInput) (*abstraction.CreateMultipartUploadOutput, error) {
+	// Parts are checksummed with SHA-256, so the object gets a composite checksum
+	out, err := o.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:            aws.String(o.bucket),
+		Key:               aws.String(input.Key),
+		ContentType:       aws.String(input.ContentType),
+		ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &abstraction.CreateMultipartUploadOutput{
+		UploadID: aws.ToString(out.UploadId),
+	}, nil
+}
+
+func (o *objectStorage) CompleteMultipartUpload(ctx context.Context, input *abstraction.CompleteMultipartUploadInput) error {
+	parts := make([]types.CompletedPart, 0, len(input.Parts))
+	for _, p := range input.Parts {
+		part := types.CompletedPart{
+			PartNumber: aws.Int32(p.PartNumber),
+			ETag:       aws.String(p.ETag),
+		}
+		if p.ChecksumSHA256 != "" {
+			part.ChecksumSHA256 = aws.String(p.ChecksumSHA256)
+		}
+		parts = append(parts, part)
+	}
+
+	_, err := o.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(o.bucket),
+		Key:             aws.String(input.Key),
+		UploadId:        aws.String(input.UploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	return err
+}
+
+func (o *objectStorage) AbortMultipartUpload(ctx context.Context, input *abstraction.AbortMultipartUploadInput) error {
+	_, err := o.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(o.bucket),
+		Key:      aws.String(input.Key),
+		UploadId: aws.String(input.UploadID),
+	})
+	return err
+}
+
+func (o *objectStorage) ListMultipartUploads(ctx context.Context, input *abstraction.ListMultipartUploadsInput) (*abstraction.ListMultipartUploadsOutput, error) {
+	result := &abstraction.ListMultipartUploadsOutput{}
+
+	var keyMarker, uploadIDMarker *string
+	for {
+		out, err := o.client.ListMultipartUploads(ctx, &s3.ListMultipartUploadsInput{
+			Bucket:         aws.String(o.bucket),
+			Prefix:         aws.String(input.Prefix),
+			KeyMarker:      keyMarker,
+			UploadIdMarker: uploadIDMarker,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, u := range out.Uploads {
+			result.Uploads = append(result.Uploads, abstraction.MultipartUpload{
+				Key:       aws.ToString(u.Key),
+				UploadID:  aws.ToString(u.UploadId),
+				Initiated: aws.ToTime(u.Initiated),
+			})
+		}
+
+		if !aws.ToBool(out.IsTruncated) {
+			return result, nil
+		}
+		keyMarker, uploadIDMarker = out.NextKeyMarker, out.NextUploadIdMarker
+	}
+}
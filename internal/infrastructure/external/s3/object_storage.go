@@ -3,12 +3,15 @@ package s3
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/url"
 
 	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
 )
 
@@ -27,19 +30,44 @@ func newObjectStorage(client *s3.Client, cfg Config) abstraction.ObjectStorage {
 
 func (o *objectStorage) HeadObject(ctx context.Context, input *abstraction.HeadObjectInput) (*abstraction.HeadObjectOutput, error) {
 	out, err := o.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(o.bucket),
+		Key:          aws.String(input.Key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, fmt.Errorf("%w: %s", abstraction.ErrObjectNotFound, input.Key)
+		}
+		return nil, err
+	}
+
+	return &abstraction.HeadObjectOutput{
+		ContentLength:  out.ContentLength,
+		ChecksumSHA256: out.ChecksumSHA256,
+	}, nil
+}
+
+func (o *objectStorage) GetObjectRange(ctx context.Context, input *abstraction.GetObjectRangeInput) (*abstraction.GetObjectRangeOutput, error) {
+	out, err := o.client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(o.bucket),
 		Key:    aws.String(input.Key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", input.Offset, input.Offset+input.Length-1)),
 	})
 	if err != nil {
-		// Convert S3 not found errors to nil response (object doesn't exist)
 		if isS3NotFound(err) {
-			return nil, errors.New("object not found")
+			return nil, fmt.Errorf("%w: %s", abstraction.ErrObjectNotFound, input.Key)
 		}
 		return nil, err
 	}
+	defer out.Body.Close()
 
-	return &abstraction.HeadObjectOutput{
-		ContentLength: out.ContentLength,
+	body, err := io.ReadAll(io.LimitReader(out.Body, input.Length))
+	if err != nil {
+		return nil, fmt.Errorf("read object range: %w", err)
+	}
+
+	return &abstraction.GetObjectRangeOutput{
+		Body: body,
 	}, nil
 }
 
@@ -55,14 +83,50 @@ func (o *objectStorage) CopyObject(ctx context.Context, input *abstraction.CopyO
 	// Build S3-specific CopySource in format "bucket/key"
 	copySource := url.PathEscape(o.bucket + "/" + input.SourceKey)
 
+	// Ask S3 to compute a SHA-256 checksum for the copy so it can be verified
 	_, err := o.client.CopyObject(ctx, &s3.CopyObjectInput{
-		Bucket:     aws.String(o.bucket),
-		Key:        aws.String(input.TargetKey),
-		CopySource: aws.String(copySource),
+		Bucket:            aws.String(o.bucket),
+		Key:               aws.String(input.TargetKey),
+		CopySource:        aws.String(copySource),
+		ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
 	})
 	return err
 }
 
+func (o *objectStorage) ListObjects(ctx context.Context, input *abstraction.ListObjectsInput) (*abstraction.ListObjectsOutput, error) {
+	req := &s3.ListObjectsV2Input{
+		Bucket: aws.String(o.bucket),
+		Prefix: aws.String(input.Prefix),
+	}
+	if input.ContinuationToken != "" {
+		req.ContinuationToken = aws.String(input.ContinuationToken)
+	}
+	if input.MaxKeys > 0 {
+		req.MaxKeys = aws.Int32(input.MaxKeys)
+	}
+
+	out, err := o.client.ListObjectsV2(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &abstraction.ListObjectsOutput{
+		Objects: make([]abstraction.StoredObject, 0, len(out.Contents)),
+	}
+	for _, obj := range out.Contents {
+		result.Objects = append(result.Objects, abstraction.StoredObject{
+			Key:          aws.ToString(obj.Key),
+			Size:         aws.ToInt64(obj.Size),
+			LastModified: aws.ToTime(obj.LastModified),
+		})
+	}
+	if aws.ToBool(out.IsTruncated) {
+		result.NextContinuationToken = aws.ToString(out.NextContinuationToken)
+	}
+
+	return result, nil
+}
+
 func isS3NotFound(err error) bool {
 	if err == nil {
 		return false
@@ -70,7 +134,7 @@ func isS3NotFound(err error) bool {
 	var ae smithy.APIError
 	if errors.As(err, &ae) {
 		switch ae.ErrorCode() {
-		case "NotFound", "NoSuchKey", "NoSuchBucket":
+		case "NotFound", "NoSuchKey":
 			return true
 		}
 	}
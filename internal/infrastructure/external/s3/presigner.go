@@ -2,12 +2,14 @@ package s3
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/Sokol111/ecommerce-image-service/internal/application"
 	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 type presigner struct {
@@ -26,17 +28,97 @@ func newPresigner(client *s3.PresignClient, s3Cfg Config, appCfg application.Con
 }
 
 func (p *presigner) PresignPutObject(ctx context.Context, input *abstraction.PresignPutObjectInput) (*abstraction.PresignPutObjectOutput, error) {
-	out, err := p.client.PresignPutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(p.bucket),
-		Key:         aws.String(input.Key),
-		ContentType: aws.String(input.ContentType),
-	}, s3.WithPresignExpires(p.ttl))
+	// Signing the checksum algorithm makes S3 reject uploads that don't carry a
+	// matching x-amz-checksum-sha256 header, and stores the checksum with the object.
+	// A signed Content-Length pins the upload to the declared size.
+	putInput := &s3.PutObjectInput{
+		Bucket:            aws.String(p.bucket),
+		Key:               aws.String(input.Key),
+		ContentType:       aws.String(input.ContentType),
+		ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
+	}
+	if input.Size > 0 {
+		putInput.ContentLength = aws.Int64(input.Size)
+	}
+	if input.ChecksumSHA256 != "" {
+		putInput.ChecksumSHA256 = aws.String(input.ChecksumSHA256)
+	}
+
+	out, err := p.client.PresignPutObject(ctx, putInput, s3.WithPresignExpires(p.ttl))
 	if err != nil {
 		return nil, err
 	}
 
 	return &abstraction.PresignPutObjectOutput{
+		URL:           out.URL,
+		TTLSeconds:    int(p.ttl.Seconds()),
+		SignedHeaders: flattenSignedHeaders(out.SignedHeader),
+	}, nil
+}
+
+func (p *presigner) PresignPostObject(ctx context.Context, input *abstraction.PresignPostObjectInput) (*abstraction.PresignPostObjectOutput, error) {
+	// The SDK adds the exact {"key": ...} condition itself
+	conditions := []interface{}{
+		[]interface{}{"content-length-range", input.MinSize, input.MaxSize},
+		map[string]string{"Content-Type": input.ContentType},
+		map[string]string{"x-amz-checksum-algorithm": string(types.ChecksumAlgorithmSha256)},
+		map[string]string{"x-amz-checksum-sha256": input.ChecksumSHA256},
+	}
+
+	out, err := p.client.PresignPostObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(input.Key),
+	}, func(o *s3.PresignPostOptions) {
+		o.Expires = p.ttl
+		o.Conditions = conditions
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string, len(out.Values)+3)
+	for name, value := range out.Values {
+		fields[name] = value
+	}
+	fields["Content-Type"] = input.ContentType
+	fields["x-amz-checksum-algorithm"] = string(types.ChecksumAlgorithmSha256)
+	fields["x-amz-checksum-sha256"] = input.ChecksumSHA256
+
+	return &abstraction.PresignPostObjectOutput{
 		URL:        out.URL,
+		Fields:     fields,
 		TTLSeconds: int(p.ttl.Seconds()),
 	}, nil
 }
+
+func (p *presigner) PresignUploadPart(ctx context.Context, input *abstraction.PresignUploadPartInput) (*abstraction.PresignUploadPartOutput, error) {
+	out, err := p.client.PresignUploadPart(ctx, &s3.UploadPartInput{
+		Bucket:            aws.String(p.bucket),
+		Key:               aws.String(input.Key),
+		UploadId:          aws.String(input.UploadID),
+		PartNumber:        aws.Int32(input.PartNumber),
+		ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
+	}, s3.WithPresignExpires(p.ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	return &abstraction.PresignUploadPartOutput{
+		URL:           out.URL,
+		TTLSeconds:    int(p.ttl.Seconds()),
+		SignedHeaders: flattenSignedHeaders(out.SignedHeader),
+	}, nil
+}
+
+func flattenSignedHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for name, values := range h {
+		name = http.CanonicalHeaderKey(name)
+		// Host is set by the HTTP client from the URL
+		if len(values) == 0 || name == "Host" {
+			continue
+		}
+		headers[name] = values[0]
+	}
+	return headers
+}
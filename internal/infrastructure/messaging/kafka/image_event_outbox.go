@@ -0,0 +1,134 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sokol111/ecommerce-commons/pkg/messaging/kafka/outbox"
+	"github.com/Sokol111/ecommerce-image-service-api/events"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+const (
+	imageEventsTopic = "image.events"
+	eventSource      = "ecommerce-image-service"
+)
+
+// imageEventOutbox converts domain events to their Avro contracts and stores them in the
+// commons outbox, which relays them to Kafka once the transaction commits
+type imageEventOutbox struct {
+	outbox outbox.Outbox
+}
+
+func newImageEventOutbox(o outbox.Outbox) abstraction.ImageEventOutbox {
+	return &imageEventOutbox{outbox: o}
+}
+
+func (o *imageEventOutbox) Append(ctx context.Context, img *image.Image, event image.Event) error {
+	payload, err := toAvroEvent(img, event)
+	if err != nil {
+		return err
+	}
+
+	// Keyed by image so that the events of an image stay ordered
+	return o.outbox.Create(ctx, outbox.Message{
+		Payload: payload,
+		Key:     img.ID,
+		Topic:   imageEventsTopic,
+	})
+}
+
+func toAvroEvent(img *image.Image, event image.Event) (events.Event, error) {
+	metadata := events.EventMetadata{
+		EventID:   event.ID,
+		EventType: string(event.Type),
+		Source:    eventSource,
+		Timestamp: event.OccurredAt,
+	}
+
+	switch event.Type {
+	case image.EventUploaded:
+		return &events.ImageUploadedEvent{
+			Metadata: metadata,
+			Payload: events.ImageUploadedPayload{
+				ImageID:   img.ID,
+				Version:   img.Version,
+				OwnerType: img.OwnerType,
+				OwnerID:   img.OwnerID,
+				Role:      img.Role,
+				Key:       img.Key,
+				Mime:      img.Mime,
+				Size:      img.Size,
+				Width:     displayWidth(img),
+				Height:    displayHeight(img),
+			},
+		}, nil
+	case image.EventPromoted:
+		return &events.ImagePromotedEvent{
+			Metadata: metadata,
+			Payload: events.ImagePromotedPayload{
+				ImageID:   img.ID,
+				Version:   img.Version,
+				DraftID:   event.DraftID,
+				ProductID: img.OwnerID,
+				Key:       img.Key,
+			},
+		}, nil
+	case image.EventReady:
+		return &events.ImageReadyEvent{
+			Metadata: metadata,
+			Payload: events.ImageReadyPayload{
+				ImageID:   img.ID,
+				Version:   img.Version,
+				OwnerType: img.OwnerType,
+				OwnerID:   img.OwnerID,
+				Role:      img.Role,
+				Key:       img.Key,
+				Mime:      img.Mime,
+				Width:     displayWidth(img),
+				Height:    displayHeight(img),
+			},
+		}, nil
+	case image.EventUpdated:
+		return &events.ImageUpdatedEvent{
+			Metadata: metadata,
+			Payload: events.ImageUpdatedPayload{
+				ImageID:   img.ID,
+				Version:   img.Version,
+				OwnerType: img.OwnerType,
+				OwnerID:   img.OwnerID,
+				Alt:       img.Alt,
+				Role:      img.Role,
+				Status:    string(img.Status),
+			},
+		}, nil
+	case image.EventDeleted:
+		return &events.ImageDeletedEvent{
+			Metadata: metadata,
+			Payload: events.ImageDeletedPayload{
+				ImageID:   img.ID,
+				Version:   img.Version,
+				OwnerType: img.OwnerType,
+				OwnerID:   img.OwnerID,
+				Hard:      event.Hard,
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported image event type: %s", event.Type)
+	}
+}
+
+func displayWidth(img *image.Image) *int {
+	if !img.HasDimensions() {
+		return nil
+	}
+	return &img.DisplayWidth
+}
+
+func displayHeight(img *image.Image) *int {
+	if !img.HasDimensions() {
+		return nil
+	}
+	return &img.DisplayHeight
+}
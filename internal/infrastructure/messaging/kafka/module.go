@@ -14,6 +14,7 @@ import (
 func Module() fx.Option {
 	return fx.Options(
 		fx.Provide(provideDeserializer),
+		fx.Provide(newImageEventOutbox),
 		consumer.RegisterHandlerAndConsumer("product-events", newProductHandler),
 	)
 }
@@ -23,6 +24,8 @@ func provideDeserializer(lc fx.Lifecycle, kafkaConf config.Config, log *zap.Logg
 	typeMap := consumer.TypeMapping{
 		"com.ecommerce.events.product.ProductCreatedEvent": reflect.TypeOf(events.ProductCreatedEvent{}),
 		"com.ecommerce.events.product.ProductUpdatedEvent": reflect.TypeOf(events.ProductUpdatedEvent{}),
+		"com.ecommerce.events.product.ProductDeletedEvent": reflect.TypeOf(events.ProductDeletedEvent{}),
+		"com.ecommerce.events.product.DraftDiscardedEvent": reflect.TypeOf(events.DraftDiscardedEvent{}),
 	}
 
 	deserializer, err := consumer.NewAvroDeserializer(kafkaConf.SchemaRegistry, typeMap)
@@ -7,17 +7,26 @@ import (
 	"github.com/Sokol111/ecommerce-commons/pkg/core/logger"
 	"github.com/Sokol111/ecommerce-commons/pkg/messaging/kafka/consumer"
 	"github.com/Sokol111/ecommerce-image-service/internal/application/command"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
 	"github.com/Sokol111/ecommerce-product-service-api/events"
 	"go.uber.org/zap"
 )
 
 type productHandler struct {
-	promoteImagesHandler command.PromoteImagesCommandHandler
+	promoteImagesHandler     command.PromoteImagesCommandHandler
+	syncProductImagesHandler command.SyncProductImagesCommandHandler
+	deleteOwnerImagesHandler command.DeleteOwnerImagesCommandHandler
 }
 
-func newProductHandler(promoteImages command.PromoteImagesCommandHandler) *productHandler {
+func newProductHandler(
+	promoteImages command.PromoteImagesCommandHandler,
+	syncProductImages command.SyncProductImagesCommandHandler,
+	deleteOwnerImages command.DeleteOwnerImagesCommandHandler,
+) *productHandler {
 	return &productHandler{
-		promoteImagesHandler: promoteImages,
+		promoteImagesHandler:     promoteImages,
+		syncProductImagesHandler: syncProductImages,
+		deleteOwnerImagesHandler: deleteOwnerImages,
 	}
 }
 
@@ -33,8 +42,11 @@ func (h *productHandler) Process(ctx context.Context, event any) error {
 	case *events.ProductCreatedEvent:
 		return h.handleProductCreated(ctx, evt)
 	case *events.ProductUpdatedEvent:
-		h.log(ctx).Warn("ProductUpdatedEvent handling not implemented yet")
-		return nil
+		return h.handleProductUpdated(ctx, evt)
+	case *events.ProductDeletedEvent:
+		return h.deleteOwnerImages(ctx, image.OwnerTypeProduct, evt.Payload.ProductID)
+	case *events.DraftDiscardedEvent:
+		return h.deleteOwnerImages(ctx, image.OwnerTypeProductDraft, evt.Payload.DraftID)
 	default:
 		// If exhaustive linter is enabled and all Event types are handled above,
 		// this case should theoretically never be reached
@@ -58,6 +70,37 @@ func (h *productHandler) handleProductCreated(ctx context.Context, e *events.Pro
 	return err
 }
 
+func (h *productHandler) handleProductUpdated(ctx context.Context, e *events.ProductUpdatedEvent) error {
+	cmd := command.SyncProductImagesCommand{
+		ProductID: e.Payload.ProductID,
+	}
+
+	// Only an event that carries the gallery, even an empty one, replaces the product's images;
+	// events without one reference the main image only
+	if e.Payload.Images != nil {
+		cmd.Complete = true
+		for _, img := range *e.Payload.Images {
+			cmd.Images = append(cmd.Images, command.ProductImageRef{
+				ImageID:  img.ImageID,
+				Role:     img.Role,
+				Position: img.Position,
+			})
+		}
+	} else if e.Payload.ImageID != nil {
+		cmd.Images = []command.ProductImageRef{{ImageID: *e.Payload.ImageID}}
+	}
+
+	return h.syncProductImagesHandler.Handle(ctx, cmd)
+}
+
+func (h *productHandler) deleteOwnerImages(ctx context.Context, ownerType, ownerID string) error {
+	_, err := h.deleteOwnerImagesHandler.Handle(ctx, command.DeleteOwnerImagesCommand{
+		OwnerType: ownerType,
+		OwnerID:   ownerID,
+	})
+	return err
+}
+
 func (h *productHandler) log(ctx context.Context) *zap.Logger {
 	return logger.FromContext(ctx).With(zap.String("component", "product-handler"))
 }
@@ -0,0 +1,45 @@
+package mongo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+// searchCursor is the keyset position of the last returned image.
+// It is serialized as base64 JSON so clients treat it as an opaque token.
+type searchCursor struct {
+	Sort      image.SortOrder `json:"s"`
+	CreatedAt time.Time       `json:"c"`
+	ID        string          `json:"i"`
+}
+
+func encodeCursor(sort image.SortOrder, img *image.Image) string {
+	raw, _ := json.Marshal(searchCursor{
+		Sort:      sort,
+		CreatedAt: img.CreatedAt,
+		ID:        img.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(token string, sort image.SortOrder) (*searchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, image.ErrInvalidCursor
+	}
+
+	var c searchCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, image.ErrInvalidCursor
+	}
+
+	// A cursor is only meaningful for the sort order it was issued with
+	if c.Sort != sort || c.ID == "" {
+		return nil, image.ErrInvalidCursor
+	}
+
+	return &c, nil
+}
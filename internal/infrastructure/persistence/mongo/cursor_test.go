@@ -0,0 +1,45 @@
+package mongo
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	token := encodeCursor(image.SortCreatedAtDesc, &image.Image{ID: "img-1", CreatedAt: created})
+
+	c, err := decodeCursor(token, image.SortCreatedAtDesc)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if c.ID != "img-1" || !c.CreatedAt.Equal(created) {
+		t.Fatalf("cursor = %+v, want img-1 at %v", c, created)
+	}
+}
+
+func TestDecodeCursorRejectsForeignTokens(t *testing.T) {
+	asc := encodeCursor(image.SortCreatedAtAsc, &image.Image{ID: "img-1", CreatedAt: time.Now()})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not base64", "%%%"},
+		{"not JSON", base64.RawURLEncoding.EncodeToString([]byte("img-1"))},
+		{"without ID", base64.RawURLEncoding.EncodeToString([]byte(`{"s":"-createdAt","c":"2026-01-02T03:04:05Z"}`))},
+		{"issued for another sort", asc},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.token, image.SortCreatedAtDesc); !errors.Is(err, image.ErrInvalidCursor) {
+				t.Fatalf("err = %v, want %v", err, image.ErrInvalidCursor)
+			}
+		})
+	}
+}
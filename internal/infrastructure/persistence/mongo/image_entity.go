@@ -5,16 +5,44 @@ import (
 )
 
 type imageEntity struct {
-	ID         string    `bson:"_id"`
-	Version    int       `bson:"version"`
-	Alt        string    `bson:"alt"`
-	OwnerType  string    `bson:"ownerType"`
-	OwnerID    string    `bson:"ownerId"`
-	Role       string    `bson:"role"`
-	Key        string    `bson:"key"`
-	Mime       string    `bson:"mime"`
-	Size       int64     `bson:"size"`
-	Status     string    `bson:"status"`
-	CreatedAt  time.Time `bson:"createdAt"`
-	ModifiedAt time.Time `bson:"modifiedAt"`
+	ID                 string            `bson:"_id"`
+	Version            int               `bson:"version"`
+	Alt                string            `bson:"alt"`
+	OwnerType          string            `bson:"ownerType"`
+	OwnerID            string            `bson:"ownerId"`
+	Role               string            `bson:"role"`
+	Position           int               `bson:"position,omitempty"`
+	Key                string            `bson:"key"`
+	Mime               string            `bson:"mime"`
+	DetectedMime       string            `bson:"detectedMime,omitempty"`
+	Size               int64             `bson:"size"`
+	Checksum           string            `bson:"checksum,omitempty"`
+	Width              int               `bson:"width,omitempty"`
+	Height             int               `bson:"height,omitempty"`
+	DisplayWidth       int               `bson:"displayWidth,omitempty"`
+	DisplayHeight      int               `bson:"displayHeight,omitempty"`
+	PerceptualHash     string            `bson:"perceptualHash,omitempty"`
+	DuplicateOf        string            `bson:"duplicateOf,omitempty"`
+	FocalPoint         *focalPointEntity `bson:"focalPoint,omitempty"`
+	Crops              []cropEntity      `bson:"crops,omitempty"`
+	Status             string            `bson:"status"`
+	FailureReason      string            `bson:"failureReason,omitempty"`
+	ProcessingAttempts int               `bson:"processingAttempts,omitempty"`
+	DeletedAt          *time.Time        `bson:"deletedAt,omitempty"`
+	RestoreStatus      string            `bson:"restoreStatus,omitempty"`
+	CreatedAt          time.Time         `bson:"createdAt"`
+	ModifiedAt         time.Time         `bson:"modifiedAt"`
+}
+
+type focalPointEntity struct {
+	X float64 `bson:"x"`
+	Y float64 `bson:"y"`
+}
+
+type cropEntity struct {
+	Name   string  `bson:"name"`
+	X      float64 `bson:"x"`
+	Y      float64 `bson:"y"`
+	Width  float64 `bson:"width"`
+	Height float64 `bson:"height"`
 }
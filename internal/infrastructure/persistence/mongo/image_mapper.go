@@ -1,6 +1,8 @@
 package mongo
 
 import (
+	"time"
+
 	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
 )
 
@@ -12,36 +14,110 @@ func newImageMapper() *imageMapper {
 
 func (m *imageMapper) ToEntity(img *image.Image) *imageEntity {
 	return &imageEntity{
-		ID:         img.ID,
-		Version:    img.Version,
-		Alt:        img.Alt,
-		OwnerType:  img.OwnerType,
-		OwnerID:    img.OwnerID,
-		Role:       img.Role,
-		Key:        img.Key,
-		Mime:       img.Mime,
-		Size:       img.Size,
-		Status:     string(img.Status),
-		CreatedAt:  img.CreatedAt,
-		ModifiedAt: img.ModifiedAt,
+		ID:                 img.ID,
+		Version:            img.Version,
+		Alt:                img.Alt,
+		OwnerType:          img.OwnerType,
+		OwnerID:            img.OwnerID,
+		Role:               img.Role,
+		Position:           img.Position,
+		Key:                img.Key,
+		Mime:               img.Mime,
+		DetectedMime:       img.DetectedMime,
+		Size:               img.Size,
+		Checksum:           img.Checksum,
+		Width:              img.Width,
+		Height:             img.Height,
+		DisplayWidth:       img.DisplayWidth,
+		DisplayHeight:      img.DisplayHeight,
+		PerceptualHash:     img.PerceptualHash,
+		DuplicateOf:        img.DuplicateOf,
+		FocalPoint:         toFocalPointEntity(img.FocalPoint),
+		Crops:              toCropEntities(img.Crops),
+		Status:             string(img.Status),
+		FailureReason:      img.FailureReason,
+		ProcessingAttempts: img.ProcessingAttempts,
+		DeletedAt:          img.DeletedAt,
+		RestoreStatus:      string(img.RestoreStatus),
+		CreatedAt:          img.CreatedAt,
+		ModifiedAt:         img.ModifiedAt,
 	}
 }
 
 func (m *imageMapper) ToDomain(e *imageEntity) *image.Image {
-	return image.Reconstruct(
-		e.ID,
-		e.Version,
-		e.Alt,
-		e.OwnerType,
-		e.OwnerID,
-		e.Role,
-		e.Key,
-		e.Mime,
-		e.Size,
-		image.ImageStatus(e.Status),
-		e.CreatedAt.UTC(),
-		e.ModifiedAt.UTC(),
-	)
+	return image.Reconstruct(image.Image{
+		ID:                 e.ID,
+		Version:            e.Version,
+		Alt:                e.Alt,
+		OwnerType:          e.OwnerType,
+		OwnerID:            e.OwnerID,
+		Role:               e.Role,
+		Position:           e.Position,
+		Key:                e.Key,
+		Mime:               e.Mime,
+		DetectedMime:       e.DetectedMime,
+		Size:               e.Size,
+		Checksum:           e.Checksum,
+		Width:              e.Width,
+		Height:             e.Height,
+		DisplayWidth:       e.DisplayWidth,
+		DisplayHeight:      e.DisplayHeight,
+		PerceptualHash:     e.PerceptualHash,
+		DuplicateOf:        e.DuplicateOf,
+		FocalPoint:         toFocalPoint(e.FocalPoint),
+		Crops:              toCrops(e.Crops),
+		Status:             image.ImageStatus(e.Status),
+		FailureReason:      e.FailureReason,
+		ProcessingAttempts: e.ProcessingAttempts,
+		DeletedAt:          utcOrNil(e.DeletedAt),
+		RestoreStatus:      image.ImageStatus(e.RestoreStatus),
+		CreatedAt:          e.CreatedAt.UTC(),
+		ModifiedAt:         e.ModifiedAt.UTC(),
+	})
+}
+
+func toFocalPointEntity(fp *image.FocalPoint) *focalPointEntity {
+	if fp == nil {
+		return nil
+	}
+	return &focalPointEntity{X: fp.X, Y: fp.Y}
+}
+
+func toFocalPoint(e *focalPointEntity) *image.FocalPoint {
+	if e == nil {
+		return nil
+	}
+	return &image.FocalPoint{X: e.X, Y: e.Y}
+}
+
+func toCropEntities(crops []image.Crop) []cropEntity {
+	if len(crops) == 0 {
+		return nil
+	}
+	out := make([]cropEntity, 0, len(crops))
+	for _, c := range crops {
+		out = append(out, cropEntity{Name: c.Name, X: c.X, Y: c.Y, Width: c.Width, Height: c.Height})
+	}
+	return out
+}
+
+func toCrops(entities []cropEntity) []image.Crop {
+	if len(entities) == 0 {
+		return nil
+	}
+	out := make([]image.Crop, 0, len(entities))
+	for _, e := range entities {
+		out = append(out, image.Crop{Name: e.Name, X: e.X, Y: e.Y, Width: e.Width, Height: e.Height})
+	}
+	return out
+}
+
+func utcOrNil(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
 }
 
 func (m *imageMapper) GetID(e *imageEntity) string {
@@ -0,0 +1,61 @@
+package mongo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
+)
+
+func TestImageMapperRoundTrip(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	img := image.Reconstruct(image.Image{
+		ID:                 "img-1",
+		Version:            3,
+		Alt:                "alt",
+		OwnerType:          image.OwnerTypeProduct,
+		OwnerID:            "p-1",
+		Role:               "gallery",
+		Position:           2,
+		Key:                "products/p-1/a.jpg",
+		Mime:               "image/jpeg",
+		DetectedMime:       "image/jpeg",
+		Size:               1024,
+		Checksum:           "c2hh",
+		Width:              800,
+		Height:             600,
+		DisplayWidth:       600,
+		DisplayHeight:      800,
+		PerceptualHash:     "00ff00ff00ff00ff",
+		DuplicateOf:        "img-0",
+		FocalPoint:         &image.FocalPoint{X: 0.25, Y: 0.75},
+		Crops:              []image.Crop{{Name: "1:1", X: 0.1, Y: 0.2, Width: 0.5, Height: 0.5}},
+		Status:             image.StatusReady,
+		ProcessingAttempts: 2,
+		CreatedAt:          created,
+		ModifiedAt:         created,
+	})
+	img.MarkAsDeleted()
+
+	m := newImageMapper()
+	entity := m.ToEntity(img)
+
+	if entity.DeletedAt == nil || !entity.DeletedAt.Equal(*img.DeletedAt) {
+		t.Fatalf("deletedAt not mapped: got %v, want %v", entity.DeletedAt, img.DeletedAt)
+	}
+	if entity.RestoreStatus != string(image.StatusReady) {
+		t.Fatalf("restoreStatus not mapped: got %q", entity.RestoreStatus)
+	}
+
+	back := m.ToDomain(entity)
+	if !reflect.DeepEqual(m.ToEntity(back), entity) {
+		t.Fatalf("round trip changed the image:\n got %+v\nwant %+v", m.ToEntity(back), entity)
+	}
+	if err := back.Restore(); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if back.Status != image.StatusReady {
+		t.Fatalf("restored status: got %q, want %q", back.Status, image.StatusReady)
+	}
+}
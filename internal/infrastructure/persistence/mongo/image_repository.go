@@ -2,18 +2,26 @@ package mongo
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/Sokol111/ecommerce-commons/pkg/persistence"
 	commonsmongo "github.com/Sokol111/ecommerce-commons/pkg/persistence/mongo"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/abstraction"
 	"github.com/Sokol111/ecommerce-image-service/internal/domain/image"
 	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type imageRepository struct {
 	*commonsmongo.GenericRepository[image.Image, imageEntity]
-	coll commonsmongo.Collection
+	coll   commonsmongo.Collection
+	tx     commonsmongo.TxManager
+	outbox abstraction.ImageEventOutbox
 }
 
-func newImageRepository(mongo commonsmongo.Mongo, mapper *imageMapper) image.Repository {
+func newImageRepository(mongo commonsmongo.Mongo, tx commonsmongo.TxManager, outbox abstraction.ImageEventOutbox, mapper *imageMapper) image.Repository {
 	coll := mongo.GetCollectionWrapper("image")
 	genericRepo := commonsmongo.NewGenericRepository(
 		coll,
@@ -23,9 +31,106 @@ func newImageRepository(mongo commonsmongo.Mongo, mapper *imageMapper) image.Rep
 	return &imageRepository{
 		GenericRepository: genericRepo,
 		coll:              coll,
+		tx:                tx,
+		outbox:            outbox,
 	}
 }
 
+// Save inserts a new image and writes its pending events to the outbox in the same transaction
+func (r *imageRepository) Save(ctx context.Context, img *image.Image) error {
+	events := img.PendingEvents()
+	if len(events) == 0 {
+		return r.save(ctx, img)
+	}
+
+	_, err := r.tx.WithTransaction(ctx, func(txCtx context.Context) (any, error) {
+		if err := r.save(txCtx, img); err != nil {
+			return nil, err
+		}
+		return nil, r.appendEvents(txCtx, img, events)
+	})
+	if err != nil {
+		return err
+	}
+
+	img.ClearEvents()
+	return nil
+}
+
+// Update stores the image and writes its pending events to the outbox in the same transaction
+func (r *imageRepository) Update(ctx context.Context, img *image.Image) (*image.Image, error) {
+	events := img.PendingEvents()
+	if len(events) == 0 {
+		return r.GenericRepository.Update(ctx, img)
+	}
+
+	result, err := r.tx.WithTransaction(ctx, func(txCtx context.Context) (any, error) {
+		updated, err := r.GenericRepository.Update(txCtx, img)
+		if err != nil {
+			return nil, err
+		}
+		// Events carry the state after the change, including the new version
+		return updated, r.appendEvents(txCtx, updated, events)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	img.ClearEvents()
+	return result.(*image.Image), nil
+}
+
+// Delete removes the image and announces the hard delete in the same transaction
+func (r *imageRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.tx.WithTransaction(ctx, func(txCtx context.Context) (any, error) {
+		img, err := r.GenericRepository.FindByID(txCtx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.GenericRepository.Delete(txCtx, id); err != nil {
+			return nil, err
+		}
+		return nil, r.appendEvents(txCtx, img, []image.Event{image.NewDeletedEvent(true)})
+	})
+	return err
+}
+
+// save inserts the image; IDs derived for idempotency and the unique object key make a
+// retried insert a duplicate
+func (r *imageRepository) save(ctx context.Context, img *image.Image) error {
+	err := r.GenericRepository.Save(ctx, img)
+	if mongodriver.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: %s", image.ErrImageAlreadyExists, img.ID)
+	}
+	return err
+}
+
+func (r *imageRepository) appendEvents(ctx context.Context, img *image.Image, events []image.Event) error {
+	for _, e := range events {
+		if err := r.outbox.Append(ctx, img, e); err != nil {
+			return fmt.Errorf("append %s event: %w", e.Type, err)
+		}
+	}
+	return nil
+}
+
+// FindByKey returns the image stored under the object key, in any status
+func (r *imageRepository) FindByKey(ctx context.Context, key string) (*image.Image, error) {
+	cur, err := r.coll.Find(ctx, bson.M{"key": key}, options.Find().SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := r.decodeAll(ctx, cur)
+	if err != nil {
+		return nil, err
+	}
+	if len(images) == 0 {
+		return nil, persistence.ErrEntityNotFound
+	}
+	return images[0], nil
+}
+
 // FindByOwner finds images by owner type and ID
 func (r *imageRepository) FindByOwner(ctx context.Context, ownerType, ownerID string, imageIDs []string) ([]*image.Image, error) {
 	filter := bson.M{
@@ -43,10 +148,130 @@ func (r *imageRepository) FindByOwner(ctx context.Context, ownerType, ownerID st
 	if err != nil {
 		return nil, err
 	}
+
+	return r.decodeAll(ctx, cur)
+}
+
+// FindDeletedBefore returns up to limit soft deleted images of the owner type deleted before the given time
+func (r *imageRepository) FindDeletedBefore(ctx context.Context, ownerType string, before time.Time, limit int) ([]*image.Image, error) {
+	filter := bson.M{
+		"status":    string(image.StatusDeleted),
+		"ownerType": ownerType,
+		"deletedAt": bson.M{"$lt": before},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "deletedAt", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cur, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.decodeAll(ctx, cur)
+}
+
+// FindExistingKeys returns which of the keys belong to an image, in any status
+func (r *imageRepository) FindExistingKeys(ctx context.Context, keys []string) (map[string]bool, error) {
+	existing := make(map[string]bool, len(keys))
+	if len(keys) == 0 {
+		return existing, nil
+	}
+
+	opts := options.Find().SetProjection(bson.M{"key": 1})
+	cur, err := r.coll.Find(ctx, bson.M{"key": bson.M{"$in": keys}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var docs []struct {
+		Key string `bson:"key"`
+	}
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	for _, d := range docs {
+		existing[d.Key] = true
+	}
+
+	return existing, nil
+}
+
+// Search finds images matching the criteria using keyset pagination on (createdAt, _id)
+func (r *imageRepository) Search(ctx context.Context, criteria image.SearchCriteria) (*image.SearchResult, error) {
+	filter := bson.M{}
+	if criteria.OwnerType != "" {
+		filter["ownerType"] = criteria.OwnerType
+	}
+	if criteria.OwnerID != "" {
+		filter["ownerId"] = criteria.OwnerID
+	}
+	if criteria.Status != "" {
+		filter["status"] = string(criteria.Status)
+	} else {
+		filter["status"] = bson.M{"$ne": string(image.StatusDeleted)}
+	}
+	if criteria.Role != "" {
+		filter["role"] = criteria.Role
+	}
+
+	createdAt := bson.M{}
+	if criteria.CreatedAfter != nil {
+		createdAt["$gte"] = *criteria.CreatedAfter
+	}
+	if criteria.CreatedBefore != nil {
+		createdAt["$lt"] = *criteria.CreatedBefore
+	}
+	if len(createdAt) > 0 {
+		filter["createdAt"] = createdAt
+	}
+
+	direction, cmp := 1, "$gt"
+	if criteria.Sort == image.SortCreatedAtDesc {
+		direction, cmp = -1, "$lt"
+	}
+
+	if criteria.Cursor != "" {
+		c, err := decodeCursor(criteria.Cursor, criteria.Sort)
+		if err != nil {
+			return nil, err
+		}
+		filter["$or"] = bson.A{
+			bson.M{"createdAt": bson.M{cmp: c.CreatedAt}},
+			bson.M{"createdAt": c.CreatedAt, "_id": bson.M{cmp: c.ID}},
+		}
+	}
+
+	// Fetch one extra document to find out whether there is a next page
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(int64(criteria.Limit + 1))
+
+	cur, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := r.decodeAll(ctx, cur)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &image.SearchResult{Images: images}
+	if len(images) > criteria.Limit {
+		result.Images = images[:criteria.Limit]
+		result.NextCursor = encodeCursor(criteria.Sort, result.Images[len(result.Images)-1])
+	}
+
+	return result, nil
+}
+
+func (r *imageRepository) decodeAll(ctx context.Context, cur *mongodriver.Cursor) ([]*image.Image, error) {
 	defer cur.Close(ctx)
 
 	var entities []imageEntity
-	if err = cur.All(ctx, &entities); err != nil {
+	if err := cur.All(ctx, &entities); err != nil {
 		return nil, err
 	}
 
@@ -58,3 +283,44 @@ func (r *imageRepository) FindByOwner(ctx context.Context, ownerType, ownerID st
 
 	return images, nil
 }
+
+// FindPerceptualHashes returns every live image that has a perceptual hash, of the owner type
+// or of all owner types when empty
+func (r *imageRepository) FindPerceptualHashes(ctx context.Context, ownerType string) ([]image.DuplicateMember, error) {
+	filter := bson.M{
+		"perceptualHash": bson.M{"$exists": true, "$ne": ""},
+		"status":         bson.M{"$ne": string(image.StatusDeleted)},
+	}
+	if ownerType != "" {
+		filter["ownerType"] = ownerType
+	}
+
+	opts := options.Find().SetProjection(bson.M{"ownerType": 1, "ownerId": 1, "perceptualHash": 1})
+	cur, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var docs []struct {
+		ID             string `bson:"_id"`
+		OwnerType      string `bson:"ownerType"`
+		OwnerID        string `bson:"ownerId"`
+		PerceptualHash string `bson:"perceptualHash"`
+	}
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	members := make([]image.DuplicateMember, 0, len(docs))
+	for _, d := range docs {
+		members = append(members, image.DuplicateMember{
+			ImageID:        d.ID,
+			OwnerType:      d.OwnerType,
+			OwnerID:        d.OwnerID,
+			PerceptualHash: d.PerceptualHash,
+		})
+	}
+
+	return members, nil
+}
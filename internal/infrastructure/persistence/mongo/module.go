@@ -9,5 +9,7 @@ func Module() fx.Option {
 	return fx.Provide(
 		newImageMapper,
 		newImageRepository,
+		newPromotionJobMapper,
+		newPromotionJobRepository,
 	)
 }
@@ -0,0 +1,26 @@
+package mongo
+
+import (
+	"time"
+)
+
+type promotionJobEntity struct {
+	ID         string                `bson:"_id"`
+	Version    int                   `bson:"version"`
+	DraftID    string                `bson:"draftId"`
+	ProductID  string                `bson:"productId"`
+	Items      []promotionItemEntity `bson:"items"`
+	Status     string                `bson:"status"`
+	Attempts   int                   `bson:"attempts"`
+	LastError  string                `bson:"lastError,omitempty"`
+	CreatedAt  time.Time             `bson:"createdAt"`
+	ModifiedAt time.Time             `bson:"modifiedAt"`
+}
+
+type promotionItemEntity struct {
+	ImageID   string `bson:"imageId"`
+	SourceKey string `bson:"sourceKey"`
+	TargetKey string `bson:"targetKey"`
+	Step      string `bson:"step"`
+	Error     string `bson:"error,omitempty"`
+}
@@ -0,0 +1,75 @@
+package mongo
+
+import (
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/promotion"
+)
+
+type promotionJobMapper struct{}
+
+func newPromotionJobMapper() *promotionJobMapper {
+	return &promotionJobMapper{}
+}
+
+func (m *promotionJobMapper) ToEntity(job *promotion.Job) *promotionJobEntity {
+	items := make([]promotionItemEntity, 0, len(job.Items))
+	for _, item := range job.Items {
+		items = append(items, promotionItemEntity{
+			ImageID:   item.ImageID,
+			SourceKey: item.SourceKey,
+			TargetKey: item.TargetKey,
+			Step:      string(item.Step),
+			Error:     item.Error,
+		})
+	}
+
+	return &promotionJobEntity{
+		ID:         job.ID,
+		Version:    job.Version,
+		DraftID:    job.DraftID,
+		ProductID:  job.ProductID,
+		Items:      items,
+		Status:     string(job.Status),
+		Attempts:   job.Attempts,
+		LastError:  job.LastError,
+		CreatedAt:  job.CreatedAt,
+		ModifiedAt: job.ModifiedAt,
+	}
+}
+
+func (m *promotionJobMapper) ToDomain(e *promotionJobEntity) *promotion.Job {
+	items := make([]promotion.Item, 0, len(e.Items))
+	for _, item := range e.Items {
+		items = append(items, promotion.Item{
+			ImageID:   item.ImageID,
+			SourceKey: item.SourceKey,
+			TargetKey: item.TargetKey,
+			Step:      promotion.Step(item.Step),
+			Error:     item.Error,
+		})
+	}
+
+	return promotion.Reconstruct(
+		e.ID,
+		e.Version,
+		e.DraftID,
+		e.ProductID,
+		items,
+		promotion.Status(e.Status),
+		e.Attempts,
+		e.LastError,
+		e.CreatedAt.UTC(),
+		e.ModifiedAt.UTC(),
+	)
+}
+
+func (m *promotionJobMapper) GetID(e *promotionJobEntity) string {
+	return e.ID
+}
+
+func (m *promotionJobMapper) GetVersion(e *promotionJobEntity) int {
+	return e.Version
+}
+
+func (m *promotionJobMapper) SetVersion(e *promotionJobEntity, version int) {
+	e.Version = version
+}
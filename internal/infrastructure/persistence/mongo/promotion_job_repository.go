@@ -0,0 +1,107 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	commonsmongo "github.com/Sokol111/ecommerce-commons/pkg/persistence/mongo"
+	"github.com/Sokol111/ecommerce-image-service/internal/domain/promotion"
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type promotionJobRepository struct {
+	*commonsmongo.GenericRepository[promotion.Job, promotionJobEntity]
+	coll commonsmongo.Collection
+}
+
+func newPromotionJobRepository(mongo commonsmongo.Mongo, mapper *promotionJobMapper) promotion.Repository {
+	coll := mongo.GetCollectionWrapper("promotionJob")
+	genericRepo := commonsmongo.NewGenericRepository(
+		coll,
+		mapper,
+	)
+
+	return &promotionJobRepository{
+		GenericRepository: genericRepo,
+		coll:              coll,
+	}
+}
+
+// Save inserts a new job; a job with the same idempotent ID is reported as promotion.ErrJobExists
+func (r *promotionJobRepository) Save(ctx context.Context, job *promotion.Job) error {
+	err := r.GenericRepository.Save(ctx, job)
+	if mongodriver.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: %s", promotion.ErrJobExists, job.ID)
+	}
+	return err
+}
+
+// FindByDraft returns the unfinished jobs of the draft, pending or failed
+func (r *promotionJobRepository) FindByDraft(ctx context.Context, draftID string) ([]*promotion.Job, error) {
+	filter := bson.M{
+		"draftId": draftID,
+		"status": bson.M{
+			"$ne": string(promotion.StatusCompleted),
+		},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+
+	cur, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.decodeAll(ctx, cur)
+}
+
+// FindPendingBefore returns up to limit pending jobs last modified before the given time
+func (r *promotionJobRepository) FindPendingBefore(ctx context.Context, before time.Time, limit int) ([]*promotion.Job, error) {
+	filter := bson.M{
+		"status":     string(promotion.StatusPending),
+		"modifiedAt": bson.M{"$lt": before},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "modifiedAt", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cur, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.decodeAll(ctx, cur)
+}
+
+// FindByStatus returns up to limit jobs with the status, newest first
+func (r *promotionJobRepository) FindByStatus(ctx context.Context, status promotion.Status, limit int) ([]*promotion.Job, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "modifiedAt", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cur, err := r.coll.Find(ctx, bson.M{"status": string(status)}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.decodeAll(ctx, cur)
+}
+
+func (r *promotionJobRepository) decodeAll(ctx context.Context, cur *mongodriver.Cursor) ([]*promotion.Job, error) {
+	defer cur.Close(ctx)
+
+	var entities []promotionJobEntity
+	if err := cur.All(ctx, &entities); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*promotion.Job, 0, len(entities))
+	mapper := &promotionJobMapper{}
+	for i := range entities {
+		jobs = append(jobs, mapper.ToDomain(&entities[i]))
+	}
+
+	return jobs, nil
+}
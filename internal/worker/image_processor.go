@@ -0,0 +1,19 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/command"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// registerImageProcessor periodically processes images left in processing by confirmation and
+// promotion, so that derivatives and hashes are never computed on the request path
+func registerImageProcessor(lc fx.Lifecycle, log *zap.Logger, cfg application.Config, handler command.ProcessPendingImagesCommandHandler) {
+	runPeriodically(lc, log, "image-processor", cfg.ProcessingInterval, func(ctx context.Context) error {
+		_, err := handler.Handle(ctx, command.ProcessPendingImagesCommand{})
+		return err
+	})
+}
@@ -0,0 +1,16 @@
+package worker
+
+import (
+	"go.uber.org/fx"
+)
+
+// Module provides background jobs
+func Module() fx.Option {
+	return fx.Options(
+		fx.Invoke(registerMultipartJanitor),
+		fx.Invoke(registerOrphanReaper),
+		fx.Invoke(registerTrashPurger),
+		fx.Invoke(registerPromotionResumer),
+		fx.Invoke(registerImageProcessor),
+	)
+}
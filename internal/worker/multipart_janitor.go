@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/command"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// registerMultipartJanitor periodically aborts multipart uploads that were never completed,
+// so their parts stop occupying storage
+func registerMultipartJanitor(lc fx.Lifecycle, log *zap.Logger, cfg application.Config, handler command.AbortStaleMultipartUploadsCommandHandler) {
+	runPeriodically(lc, log, "multipart-janitor", cfg.MultipartCleanupInterval, func(ctx context.Context) error {
+		_, err := handler.Handle(ctx, command.AbortStaleMultipartUploadsCommand{
+			OlderThan: cfg.MultipartStaleAfter,
+		})
+		return err
+	})
+}
@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/command"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type orphanReaperMetrics struct {
+	scanned      metric.Int64Counter
+	orphaned     metric.Int64Counter
+	deleted      metric.Int64Counter
+	deletedBytes metric.Int64Counter
+}
+
+func newOrphanReaperMetrics() (*orphanReaperMetrics, error) {
+	meter := otel.Meter("ecommerce-image-service/worker")
+
+	scanned, err := meter.Int64Counter("image.orphan_reaper.scanned",
+		metric.WithDescription("Stored objects inspected by the orphan reaper"))
+	if err != nil {
+		return nil, err
+	}
+	orphaned, err := meter.Int64Counter("image.orphan_reaper.orphaned",
+		metric.WithDescription("Stored objects without an image past the grace period"))
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := meter.Int64Counter("image.orphan_reaper.deleted",
+		metric.WithDescription("Orphaned objects deleted by the orphan reaper"))
+	if err != nil {
+		return nil, err
+	}
+	deletedBytes, err := meter.Int64Counter("image.orphan_reaper.deleted_bytes",
+		metric.WithDescription("Storage reclaimed by the orphan reaper"),
+		metric.WithUnit("By"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &orphanReaperMetrics{
+		scanned:      scanned,
+		orphaned:     orphaned,
+		deleted:      deleted,
+		deletedBytes: deletedBytes,
+	}, nil
+}
+
+func (m *orphanReaperMetrics) record(ctx context.Context, result *command.ReapOrphanedUploadsResult, dryRun bool) {
+	for _, pr := range result.Prefixes {
+		attrs := metric.WithAttributes(
+			attribute.String("prefix", pr.Prefix),
+			attribute.Bool("dry_run", dryRun),
+		)
+		m.scanned.Add(ctx, int64(pr.Scanned), attrs)
+		m.orphaned.Add(ctx, int64(pr.Orphaned), attrs)
+		m.deleted.Add(ctx, int64(pr.Deleted), attrs)
+		m.deletedBytes.Add(ctx, pr.DeletedBytes, attrs)
+	}
+}
+
+// registerOrphanReaper periodically deletes uploaded objects that never got an image,
+// typically presigned uploads that were never confirmed
+func registerOrphanReaper(lc fx.Lifecycle, log *zap.Logger, cfg application.Config, handler command.ReapOrphanedUploadsCommandHandler) error {
+	metrics, err := newOrphanReaperMetrics()
+	if err != nil {
+		return err
+	}
+
+	runPeriodically(lc, log, "orphan-reaper", cfg.OrphanReapInterval, func(ctx context.Context) error {
+		result, err := handler.Handle(ctx, command.ReapOrphanedUploadsCommand{
+			OlderThan: cfg.OrphanGracePeriod,
+			DryRun:    cfg.OrphanReapDryRun,
+		})
+		if result != nil {
+			metrics.record(ctx, result, cfg.OrphanReapDryRun)
+		}
+		return err
+	})
+	return nil
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// runPeriodically runs fn every interval for the lifetime of the application.
+// Errors are logged and the next run happens on schedule.
+func runPeriodically(lc fx.Lifecycle, log *zap.Logger, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	log = log.With(zap.String("component", name))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						if err := fn(ctx); err != nil {
+							log.Error("periodic job failed", zap.Error(err))
+						}
+					}
+				}
+			}()
+			log.Info("periodic job started", zap.Duration("interval", interval))
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+}
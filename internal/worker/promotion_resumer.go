@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/command"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// registerPromotionResumer resumes promotion jobs interrupted by a crash or shutdown,
+// once right after startup and then periodically
+func registerPromotionResumer(lc fx.Lifecycle, log *zap.Logger, cfg application.Config, handler command.ResumePromotionsCommandHandler) {
+	resume := func(ctx context.Context) error {
+		_, err := handler.Handle(ctx, command.ResumePromotionsCommand{
+			StaleAfter: cfg.PromotionResumeAfter,
+		})
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				if err := resume(ctx); err != nil {
+					log.Error("failed to resume promotion jobs", zap.Error(err), zap.String("component", "promotion-resumer"))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+
+	runPeriodically(lc, log, "promotion-resumer", cfg.PromotionResumeAfter, resume)
+}
@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/Sokol111/ecommerce-image-service/internal/application"
+	"github.com/Sokol111/ecommerce-image-service/internal/application/command"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// registerTrashPurger periodically hard deletes images, record and object, that have been
+// in the trash longer than the retention of their owner type
+func registerTrashPurger(lc fx.Lifecycle, log *zap.Logger, cfg application.Config, handler command.PurgeDeletedImagesCommandHandler) {
+	runPeriodically(lc, log, "trash-purger", cfg.TrashPurgeInterval, func(ctx context.Context) error {
+		_, err := handler.Handle(ctx, command.PurgeDeletedImagesCommand{
+			Retention: cfg.TrashRetentionByOwnerType(),
+		})
+		return err
+	})
+}